@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"compiler-go/internal/parser"
+	"compiler-go/pkg/ast"
+)
+
+var astCommand = &command{
+	name:    "ast",
+	args:    "file.dart",
+	summary: "Print the parsed syntax tree of a Dart file as JSON or YAML",
+	help: `Ast parses a single Dart file and prints its compilation unit, with
+source spans, as JSON or YAML. Diagnostics are written to stderr; if any
+is an error, nothing is printed and ast exits with status 1.`,
+	flags: func(fs *flag.FlagSet) {
+		fs.String("format", "json", "Output format: json or yaml")
+	},
+	run: runAST,
+}
+
+func runAST(fs *flag.FlagSet) int {
+	if fs.NArg() != 1 {
+		return usageError(fs, "expected one Dart file")
+	}
+	format := fs.Lookup("format").Value.String()
+	if format != "json" && format != "yaml" {
+		return usageError(fs, "unknown format %q", format)
+	}
+
+	path := fs.Arg(0)
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return fail(fs, err)
+	}
+	unit, diags := parser.NewParser().ParseUnit(path, string(source))
+	printDiagnostics(diags, string(source))
+	if unit == nil {
+		return exitError
+	}
+
+	var out []byte
+	if format == "yaml" {
+		out, err = yaml.Marshal(ast.Dump(unit))
+	} else {
+		out, err = json.MarshalIndent(ast.Dump(unit), "", "  ")
+		out = append(out, '\n')
+	}
+	if err != nil {
+		return fail(fs, fmt.Errorf("error encoding %s: %v", path, err))
+	}
+	os.Stdout.Write(out)
+	return exitOK
+}
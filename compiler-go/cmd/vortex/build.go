@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var buildCommand = &command{
+	name:    "build",
+	args:    "[project-dir | file.dart]",
+	summary: "Compile a project or Dart file to HTML, CSS and JavaScript",
+	help: `Build compiles a project, or a single Dart file, to the output directory.
+A project directory (default ".") compiles its entry, main.dart or else
+lib/main.dart, to app.js and each other file in lib/ to lib/<name>.js; a
+single file compiles to app.js. These are ES modules sharing the runtime
+module vortex-runtime.js, which is written alongside along with the page
+templates.
+
+Build caches the generated code of each file in ` + cacheDir + ` and reuses it
+while the file, the widgets it uses, the config and the compiler are
+unchanged. Use -no-cache to compile everything and 'vortex clean' to
+delete the cache.
+
+With -bundle, build instead follows the imports from the entry and writes
+the files it reaches, and the parts of the runtime they use, as a single
+script named after a hash of its content, app.[hash].js, pointing
+index.html at it. Bundles are always compiled from scratch.
+
+With -minify, the generated code and the runtime are stripped of comments
+and whitespace, local variables are given short names, and the runtime
+keeps only the widget methods the compiled files use. Minified output is
+cached separately from the rest.
+
+With -source-map, each generated script gets a source map, named after it
+with .map appended, that points its widget constructors, callbacks,
+methods and statements to their Dart source, which the map embeds.
+Browser developer tools then show the Dart code in stack traces and the
+debugger.`,
+	flags: func(fs *flag.FlagSet) {
+		outputFlag(fs)
+		jobsFlag(fs)
+		sourceMapFlag(fs)
+		fs.BoolVar(&verbose, "v", false, "Report each generated file")
+		fs.Bool("no-cache", false, "Compile every file, ignoring and replacing the build cache")
+		fs.Bool("bundle", false, "Write a single script, app.[hash].js, for the files the entry imports")
+		fs.Bool("minify", false, "Minify the output and drop the runtime widgets it does not use")
+	},
+	run: runBuild,
+}
+
+var cleanCommand = &command{
+	name:    "clean",
+	args:    "[project-dir | file.dart]",
+	summary: "Delete the build cache",
+	help: `Clean deletes the build cache of a project, the ` + cacheDir + ` directory,
+so that the next build compiles every file.`,
+	run: runClean,
+}
+
+var checkCommand = &command{
+	name:    "check",
+	args:    "[project-dir | file.dart]",
+	summary: "Parse and validate a project or Dart file without writing output",
+	help: `Check parses and validates a project, or a single Dart file, without
+writing any output. It exits with status 1 if there are errors.`,
+	flags: jobsFlag,
+	run:   runCheck,
+}
+
+func runBuild(fs *flag.FlagSet) int {
+	p, code := projectArg(fs)
+	if p == nil {
+		return code
+	}
+	p.minify = fs.Lookup("minify").Value.String() == "true"
+	var err error
+	if fs.Lookup("bundle").Value.String() == "true" {
+		err = p.bundle()
+	} else {
+		err = p.compileWithCache(fs.Lookup("no-cache").Value.String() != "true")
+	}
+	if err != nil {
+		return fail(fs, err)
+	}
+	logf("Compilation completed successfully!\n")
+	return exitOK
+}
+
+func runCheck(fs *flag.FlagSet) int {
+	p, code := projectArg(fs)
+	if p == nil {
+		return code
+	}
+	if err := p.compile(false); err != nil {
+		return fail(fs, err)
+	}
+	return exitOK
+}
+
+func runClean(fs *flag.FlagSet) int {
+	path := projectPath(fs)
+	if fs.NArg() > 1 {
+		return usageError(fs, "too many arguments")
+	}
+	if info, err := os.Stat(path); err != nil {
+		return fail(fs, err)
+	} else if !info.IsDir() {
+		path = filepath.Dir(path)
+	}
+	dir := filepath.Join(path, cacheDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return fail(fs, err)
+	}
+	os.Remove(filepath.Dir(dir)) // .vortex, if nothing else is in it
+	fmt.Printf("Removed: %s\n", dir)
+	return exitOK
+}
+
+// projectArg loads the project named by the command's only argument,
+// defaulting to the working directory. On failure it reports the error
+// and returns a nil project and the exit code.
+func projectArg(fs *flag.FlagSet) (*project, int) {
+	path := "."
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		path = fs.Arg(0)
+	default:
+		return nil, usageError(fs, "too many arguments")
+	}
+
+	outputDir := ""
+	if f := fs.Lookup("o"); f != nil {
+		outputDir = f.Value.String()
+	}
+	p, err := loadProject(path, outputDir, jobsArg(fs))
+	if err != nil {
+		return nil, fail(fs, err)
+	}
+	if f := fs.Lookup("source-map"); f != nil {
+		p.sourceMaps = f.Value.String() == "true"
+	}
+	return p, exitOK
+}
+
+// jobsArg returns the value of the command's -j flag
+func jobsArg(fs *flag.FlagSet) int {
+	return fs.Lookup("j").Value.(flag.Getter).Get().(int)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"compiler-go/internal/generator"
+	"compiler-go/internal/minify"
+	"compiler-go/internal/sourcemap"
+	"compiler-go/pkg/ast"
+)
+
+// bundleName matches the file names of bundles, app.[hash].js, and of
+// their source maps
+var bundleName = regexp.MustCompile(`^app\.[0-9a-f]{8}\.js(\.map)?$`)
+
+// bundle compiles the files reachable through imports from the entry into
+// a single script, app.[hash].js, named after its content so that it can
+// be cached indefinitely, and writes index.html to load it. Bundles of
+// earlier builds are removed.
+func (p *project) bundle() error {
+	var entry *sourceFile
+	for _, file := range p.files {
+		if file.entry {
+			entry = file
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("no main.dart or lib/main.dart to bundle from")
+	}
+	if err := p.parse(nil); err != nil {
+		return err
+	}
+
+	// Generate the entry, then the files it imports, and so on
+	byPath := make(map[string]*sourceFile, len(p.files))
+	for _, file := range p.files {
+		byPath[file.path] = file
+	}
+	modules := make(map[*sourceFile]*generator.Module)
+	for frontier := []*sourceFile{entry}; len(frontier) > 0; {
+		generated := make([]*generator.Module, len(frontier))
+		err := p.eachModule(frontier, func(jsGenerator *generator.JSGenerator, i int) (err error) {
+			generated[i], err = jsGenerator.BundleModule(frontier[i].unit, p.moduleOptions(frontier[i]))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		var next []*sourceFile
+		for i, m := range generated {
+			modules[frontier[i]] = m
+			for _, imp := range m.Imports {
+				if file := byPath[imp.File]; modules[file] == nil && !contains(next, file) {
+					next = append(next, file)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	ordered, err := bundleOrder(entry, modules, byPath)
+	if err != nil {
+		return err
+	}
+	units := make([]*ast.CompilationUnit, len(ordered))
+	for i, m := range ordered {
+		units[i] = byPath[m.File].unit
+	}
+	jsGenerator := generator.NewJSGenerator()
+	jsGenerator.SetConfig(p.config)
+	code, err := jsGenerator.Bundle(ordered, generator.UsedWidgets(units))
+	if err != nil {
+		return err
+	}
+	if p.minify {
+		if code, err = minify.JS(code); err != nil {
+			return fmt.Errorf("error minifying the bundle: %v", err)
+		}
+	}
+
+	// The name is taken from the code without its source map marks, which
+	// hold the paths of the Dart files, so that it does not depend on
+	// -source-map
+	stripped, _ := sourcemap.Extract(code)
+	name := "app." + hashString(stripped)[:8] + ".js"
+	outputPath := filepath.Join(p.outputDir, name)
+	out, err := p.output(code, outputPath)
+	if err != nil {
+		return err
+	}
+	if err := out.write(outputPath); err != nil {
+		return err
+	}
+	logf("Generated: %s (%d of %d files)\n", outputPath, len(ordered), len(p.files))
+	if entries, err := os.ReadDir(p.outputDir); err == nil {
+		for _, e := range entries {
+			if bundleName.MatchString(e.Name()) && e.Name() != name && (out.sourceMap == nil || e.Name() != name+".map") {
+				if err := os.Remove(filepath.Join(p.outputDir, e.Name())); err == nil {
+					logf("Removed: %s\n", filepath.Join(p.outputDir, e.Name()))
+				}
+			}
+		}
+	}
+	return p.copyTemplates(name)
+}
+
+// bundleOrder orders modules so that each follows the modules it imports,
+// ending with the entry. Imports must not form a cycle, since a module
+// can only use the classes of modules that ran before it.
+func bundleOrder(entry *sourceFile, modules map[*sourceFile]*generator.Module, byPath map[string]*sourceFile) ([]*generator.Module, error) {
+	var ordered []*generator.Module
+	done := make(map[*sourceFile]bool)
+	var path []string // files being visited, to report a cycle
+	var visit func(file *sourceFile) error
+	visit = func(file *sourceFile) error {
+		for i, visiting := range path {
+			if visiting == file.path {
+				return fmt.Errorf("cannot bundle cyclic imports: %s", strings.Join(append(path[i:], file.path), " -> "))
+			}
+		}
+		if done[file] {
+			return nil
+		}
+		path = append(path, file.path)
+		for _, imp := range modules[file].Imports {
+			if err := visit(byPath[imp.File]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		done[file] = true
+		ordered = append(ordered, modules[file])
+		return nil
+	}
+	return ordered, visit(entry)
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"compiler-go/internal/diag"
+	"compiler-go/pkg/ast"
+)
+
+// cacheDir is the directory of the build cache, relative to the project
+const cacheDir = ".vortex/cache"
+
+// version is the compiler version, set at link time with
+// -ldflags "-X main.version=..."
+var version = "dev"
+
+// buildCache is the persistent cache of generated output. It records,
+// for each source file, the hash of its content, the widgets it declares
+// and uses, and where its generated output and diagnostics are stored.
+// The whole cache is discarded when the compiler, the config, the output
+// directory or the minify or source map setting changes.
+type buildCache struct {
+	dir      string
+	manifest cacheManifest
+}
+
+// cacheManifest is stored as manifest.json in the cache directory
+type cacheManifest struct {
+	Compiler   string                 `json:"compiler"`             // compiler stamp
+	Config     string                 `json:"config"`               // hash of the config file
+	Minify     bool                   `json:"minify,omitempty"`     // the output is minified
+	SourceMaps bool                   `json:"sourceMaps,omitempty"` // the output has source maps
+	OutputDir  string                 `json:"outputDir"`            // which source map sources are relative to
+	Files      map[string]*cacheEntry `json:"files"`                // by path relative to the project
+}
+
+// cacheEntry is the cached compilation of one source file. Besides the
+// file's content, the output depends on where it is written, which its
+// imports are relative to, on whether it is the entry, which bootstraps
+// the app, and on the package name, which package: imports resolve with.
+type cacheEntry struct {
+	Hash        string              `json:"hash"`
+	Path        string              `json:"path"` // output path the code was generated for
+	Entry       bool                `json:"entry,omitempty"`
+	Package     string              `json:"package,omitempty"`
+	Declares    []string            `json:"declares,omitempty"`
+	Uses        map[string]cacheDep `json:"uses,omitempty"`
+	Diagnostics string              `json:"diagnostics,omitempty"` // formatted
+	Output      string              `json:"output"`                // file in the cache directory
+	SourceMap   string              `json:"sourceMap,omitempty"`   // file in the cache directory
+}
+
+// cacheDep records the declaration of a widget used by a file when the
+// file was generated. File is empty if the project did not declare the
+// widget.
+type cacheDep struct {
+	File string `json:"file,omitempty"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// openCache loads the cache of project p. A missing or unreadable cache,
+// or one written by another compiler, for another config or output
+// directory or with other minify or source map settings, is empty.
+func openCache(p *project) *buildCache {
+	c := &buildCache{dir: filepath.Join(p.sourceDir, cacheDir)}
+	stamp := cacheManifest{Compiler: compilerStamp(), Config: hashFile(p.config.Path), Minify: p.minify, SourceMaps: p.sourceMaps, OutputDir: p.outputDir}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, "manifest.json"))
+	if err == nil && json.Unmarshal(data, &c.manifest) == nil &&
+		c.manifest.Compiler == stamp.Compiler && c.manifest.Config == stamp.Config &&
+		c.manifest.Minify == stamp.Minify && c.manifest.SourceMaps == stamp.SourceMaps &&
+		c.manifest.OutputDir == stamp.OutputDir && c.manifest.Files != nil {
+		return c
+	}
+	stamp.Files = make(map[string]*cacheEntry)
+	c.manifest = stamp
+	return c
+}
+
+// save writes the manifest
+func (c *buildCache) save() error {
+	data, err := json.MarshalIndent(c.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(c.dir, "manifest.json"), data)
+}
+
+// compileWithCache compiles the project like compile, taking the output
+// of unchanged files from the cache. A file is regenerated if its content
+// changed or if a widget it uses is now declared by another file or in
+// different content. Only the changed files and the files declaring
+// widgets used by regenerated files are parsed. If useCache is false,
+// every file is compiled and the cache is replaced.
+func (p *project) compileWithCache(useCache bool) error {
+	c := openCache(p)
+	parallel(len(p.files), p.jobs, func(_, i int) {
+		p.files[i].err = p.files[i].readSource()
+	})
+	if err := joinErrors(p.files); err != nil {
+		return err
+	}
+
+	// Parse the files that changed to learn what they declare
+	entries := make(map[*sourceFile]*cacheEntry)
+	hashes := make(map[string]string)
+	var changed []*sourceFile
+	for _, file := range p.files {
+		hash := hashString(file.source)
+		hashes[p.relPath(file)] = hash
+		if entry := c.manifest.Files[p.relPath(file)]; entry != nil && entry.matches(p, file, hash) {
+			entries[file] = entry
+		} else {
+			changed = append(changed, file)
+		}
+	}
+	if !useCache {
+		return p.compileAndCache(c, hashes)
+	}
+	if err := p.parseAndReport(changed); err != nil {
+		return err
+	}
+
+	// Find the file declaring each widget. If a widget is declared twice
+	// the cache is not used, so that duplicates are reported as usual.
+	declarers := make(map[string]*sourceFile)
+	for _, file := range p.files {
+		for _, name := range declaredWidgets(file, entries[file]) {
+			if _, ok := declarers[name]; ok {
+				return p.compileAndCache(c, hashes)
+			}
+			declarers[name] = file
+		}
+	}
+	currentDep := func(name string) cacheDep {
+		if file, ok := declarers[name]; ok {
+			return cacheDep{File: p.relPath(file), Hash: hashes[p.relPath(file)]}
+		}
+		return cacheDep{}
+	}
+
+	// Regenerate the changed files and the files whose dependencies
+	// changed, parsing the files that declare the widgets they use
+	stale := make(map[*sourceFile]bool)
+	for _, file := range p.files {
+		entry := entries[file]
+		if entry == nil {
+			stale[file] = true
+			continue
+		}
+		for name, dep := range entry.Uses {
+			if currentDep(name) != dep {
+				stale[file] = true
+				break
+			}
+		}
+	}
+	var dirty, needed []*sourceFile
+	for _, file := range p.files {
+		if !stale[file] {
+			continue
+		}
+		dirty = append(dirty, file)
+		uses := usedWidgets(file.unit)
+		if file.unit == nil {
+			uses = make(map[string]bool)
+			for name := range entries[file].Uses {
+				uses[name] = true
+			}
+		}
+		for name := range uses {
+			if declarer, ok := declarers[name]; ok {
+				needed = append(needed, declarer)
+			}
+		}
+		needed = append(needed, file)
+	}
+	var unparsed []*sourceFile
+	for _, file := range p.files {
+		if file.unit == nil && contains(needed, file) {
+			unparsed = append(unparsed, file)
+		}
+	}
+	if err := p.parseAndReport(unparsed); err != nil {
+		return err
+	}
+	var parsed []*sourceFile
+	for _, file := range p.files {
+		if file.unit != nil {
+			parsed = append(parsed, file)
+		}
+	}
+	p.addSymbols(parsed)
+
+	outputs, err := p.generate(dirty, true)
+	if err != nil {
+		return err
+	}
+
+	// Restore the output of the files that are still up to date
+	var restored []*sourceFile
+	for _, file := range p.files {
+		if !stale[file] {
+			restored = append(restored, file)
+		}
+	}
+	parallel(len(restored), p.jobs, func(_, i int) {
+		file := restored[i]
+		file.err = copyFile(filepath.Join(c.dir, entries[file].Output), file.outputPath)
+		if file.err == nil && entries[file].SourceMap != "" {
+			file.err = copyFile(filepath.Join(c.dir, entries[file].SourceMap), file.outputPath+".map")
+		}
+	})
+	for _, file := range restored {
+		fmt.Fprint(os.Stderr, entries[file].Diagnostics)
+	}
+	if err := joinErrors(restored); err != nil {
+		return err
+	}
+	logf("Reused %d of %d files from the cache\n", len(restored), len(p.files))
+
+	if err := c.store(p, dirty, outputs, hashes, currentDep); err != nil {
+		return err
+	}
+	return p.writeAssets(p.runtimeWidgets(entries))
+}
+
+// compileAndCache compiles every file, then replaces the cache with the
+// result
+func (p *project) compileAndCache(c *buildCache, hashes map[string]string) error {
+	c.manifest.Files = make(map[string]*cacheEntry)
+	var unparsed []*sourceFile
+	for _, file := range p.files {
+		if file.unit == nil {
+			unparsed = append(unparsed, file)
+		}
+	}
+	if err := p.parseAndReport(unparsed); err != nil {
+		return err
+	}
+	p.addSymbols(p.files)
+	outputs, err := p.generate(p.files, true)
+	if err != nil {
+		return err
+	}
+	if err := c.store(p, p.files, outputs, hashes, func(name string) cacheDep {
+		if file := p.symbols.File(name); file != "" {
+			rel := p.relPath(&sourceFile{path: file})
+			return cacheDep{File: rel, Hash: hashes[rel]}
+		}
+		return cacheDep{}
+	}); err != nil {
+		return err
+	}
+	return p.writeAssets(p.runtimeWidgets(nil))
+}
+
+// parseAndReport parses files and prints their diagnostics
+func (p *project) parseAndReport(files []*sourceFile) error {
+	p.parseFiles(files)
+	for _, file := range files {
+		printDiagnostics(file.diags, file.source)
+	}
+	return joinErrors(files)
+}
+
+// store records the generated output of files in the cache, drops entries
+// of files no longer in the project and saves the manifest
+func (c *buildCache) store(p *project, files []*sourceFile, outputs []output, hashes map[string]string, dep func(name string) cacheDep) error {
+	for i, file := range files {
+		rel := p.relPath(file)
+		entry := &cacheEntry{
+			Hash:    hashes[rel],
+			Path:    file.outputPath,
+			Entry:   file.entry,
+			Package: p.pkg,
+			Output:  hashString(rel) + ".js",
+			Uses:    make(map[string]cacheDep),
+		}
+		for _, class := range file.unit.Classes {
+			entry.Declares = append(entry.Declares, class.Name)
+		}
+		for name := range usedWidgets(file.unit) {
+			entry.Uses[name] = dep(name)
+		}
+		var diagnostics strings.Builder
+		for _, d := range file.diags {
+			diagnostics.WriteString(diag.Format(d, file.source))
+		}
+		entry.Diagnostics = diagnostics.String()
+		if err := writeFile(filepath.Join(c.dir, entry.Output), []byte(outputs[i].code)); err != nil {
+			return err
+		}
+		if outputs[i].sourceMap != nil {
+			entry.SourceMap = entry.Output + ".map"
+			if err := writeFile(filepath.Join(c.dir, entry.SourceMap), outputs[i].sourceMap); err != nil {
+				return err
+			}
+		}
+		c.manifest.Files[rel] = entry
+	}
+	for rel := range c.manifest.Files {
+		if _, ok := hashes[rel]; !ok {
+			os.Remove(filepath.Join(c.dir, c.manifest.Files[rel].Output))
+			if c.manifest.Files[rel].SourceMap != "" {
+				os.Remove(filepath.Join(c.dir, c.manifest.Files[rel].SourceMap))
+			}
+			delete(c.manifest.Files, rel)
+		}
+	}
+	return c.save()
+}
+
+// matches reports whether the entry holds the output of file with
+// content of the given hash, generated as file is generated now
+func (e *cacheEntry) matches(p *project, file *sourceFile, hash string) bool {
+	return e.Hash == hash && e.Path == file.outputPath && e.Entry == file.entry && e.Package == p.pkg
+}
+
+// relPath returns the path of file relative to the project, which keys
+// the file in the cache
+func (p *project) relPath(file *sourceFile) string {
+	if rel, err := filepath.Rel(p.sourceDir, file.path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(file.path)
+}
+
+// declaredWidgets returns the widgets declared by file, from its unit if
+// it has been parsed and otherwise from its cache entry
+func declaredWidgets(file *sourceFile, entry *cacheEntry) []string {
+	if file.unit == nil {
+		return entry.Declares
+	}
+	var names []string
+	for _, class := range file.unit.Classes {
+		names = append(names, class.Name)
+	}
+	return names
+}
+
+// usedWidgets returns the names of the widgets unit constructs
+func usedWidgets(unit *ast.CompilationUnit) map[string]bool {
+	names := make(map[string]bool)
+	if unit == nil {
+		return names
+	}
+	ast.Inspect(unit, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.WidgetNode:
+			names[n.Name] = true
+		case *ast.Call:
+			if n.Widget != nil {
+				names[n.Widget.Name] = true
+			}
+		}
+		return true
+	})
+	return names
+}
+
+func contains(files []*sourceFile, file *sourceFile) bool {
+	for _, f := range files {
+		if f == file {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFile copies the file at src to dst, creating dst's directory
+func copyFile(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("error reading cache: %v", err)
+	}
+	return writeFile(dst, content)
+}
+
+// compilerStamp identifies the compiler build, so that output cached by
+// another build is not reused. Builds from a clean checkout are
+// identified by their version and commit; others by a hash of the
+// executable.
+var compilerStamp = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		var revision, modified string
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value
+			}
+		}
+		if revision != "" && modified == "false" {
+			return version + "+" + revision
+		}
+	}
+	if exe, err := os.Executable(); err == nil {
+		return version + "+" + hashFile(exe)
+	}
+	return version
+})
+
+// hashFile returns the hex SHA-256 of a file's content, or "" if it
+// cannot be read
+func hashFile(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashString returns the hex SHA-256 of s
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// cacheFixture is a project built with the build cache: main.dart, the
+// entry, uses the Counter widget of lib/counter.dart, and lib/label.dart,
+// which nothing uses, has a warning
+type cacheFixture struct {
+	dir        string
+	out        string
+	minify     bool
+	sourceMaps bool
+	stamp      string // compiler stamp
+}
+
+var cacheFixtureFiles = map[string]string{
+	"vortex.config.yml": "compiler:\n  useFlutterWind: false\n",
+	"pubspec.yaml":      "name: app\n",
+	"main.dart": `import 'package:flutter/material.dart';
+import 'package:app/counter.dart';
+
+void main() {
+  runApp(const App());
+}
+
+class App extends StatelessWidget {
+  const App({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return MaterialApp(home: Counter());
+  }
+}
+`,
+	"lib/counter.dart": counterSource("count"),
+	"lib/label.dart": `import 'package:flutter/material.dart';
+
+class Label extends StatelessWidget {
+  const Label({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Placeholder();
+  }
+}
+`,
+}
+
+// counterSource returns a file declaring the Counter widget showing text
+func counterSource(text string) string {
+	return `import 'package:flutter/material.dart';
+
+class Counter extends StatelessWidget {
+  const Counter({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Text('` + text + `');
+  }
+}
+`
+}
+
+func newCacheFixture(t *testing.T) *cacheFixture {
+	t.Helper()
+	dir := t.TempDir()
+	f := &cacheFixture{dir: filepath.Join(dir, "app"), out: filepath.Join(dir, "out"), stamp: "test"}
+	for name, content := range cacheFixtureFiles {
+		f.write(t, name, content)
+	}
+
+	stamp, wasVerbose := compilerStamp, verbose
+	t.Cleanup(func() { compilerStamp, verbose = stamp, wasVerbose })
+	compilerStamp = func() string { return f.stamp }
+	verbose = true
+	return f
+}
+
+// write writes a file of the project
+func (f *cacheFixture) write(t *testing.T, name, content string) {
+	t.Helper()
+	if err := writeFile(filepath.Join(f.dir, name), []byte(content)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// build builds the project, using the cache if useCache is set, and
+// returns the output files it generated, relative to the output
+// directory, and what it printed to standard error
+func (f *cacheFixture) build(t *testing.T, useCache bool) ([]string, string) {
+	t.Helper()
+	p, err := loadProject(f.dir, f.out, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.minify, p.sourceMaps = f.minify, f.sourceMaps
+
+	var buildErr error
+	stdout, stderr := capture(t, func() { buildErr = p.compileWithCache(useCache) })
+	if buildErr != nil {
+		t.Fatalf("build failed: %v\n%s", buildErr, stderr)
+	}
+	var generated []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if path, ok := strings.CutPrefix(line, "Generated: "); ok && strings.HasSuffix(path, ".js") && filepath.Base(path) != "vortex-runtime.js" {
+			rel, err := filepath.Rel(f.out, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			generated = append(generated, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(generated)
+	return generated, stderr
+}
+
+// outputs returns the content of the generated scripts and source maps
+func (f *cacheFixture) outputs(t *testing.T) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(f.out, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !(strings.HasSuffix(path, ".js") || strings.HasSuffix(path, ".map")) {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		files[path] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// capture runs fn and returns what it printed to standard output and
+// standard error
+func capture(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+	stdout, stderr := os.Stdout, os.Stderr
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	var files [2]*os.File
+	for i := range files {
+		file, err := os.CreateTemp(t.TempDir(), "output")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		files[i] = file
+	}
+	os.Stdout, os.Stderr = files[0], files[1]
+	fn()
+
+	var output [2]string
+	for i, file := range files {
+		content, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		output[i] = string(content)
+	}
+	return output[0], output[1]
+}
+
+func TestBuildCache(t *testing.T) {
+	all := []string{"app.js", "lib/counter.js", "lib/label.js"}
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, f *cacheFixture) // before the first build
+		change func(t *testing.T, f *cacheFixture) // before the second
+		want   []string
+	}{
+		{
+			name: "unchanged",
+		},
+		{
+			name: "content",
+			change: func(t *testing.T, f *cacheFixture) {
+				f.write(t, "lib/label.dart", cacheFixtureFiles["lib/label.dart"]+"\n// edited\n")
+			},
+			want: []string{"lib/label.js"},
+		},
+		{
+			name:   "content of a dependency",
+			change: func(t *testing.T, f *cacheFixture) { f.write(t, "lib/counter.dart", counterSource("total")) },
+			want:   []string{"app.js", "lib/counter.js"},
+		},
+		{
+			name: "declaration of a dependency moved",
+			change: func(t *testing.T, f *cacheFixture) {
+				f.write(t, "lib/counter.dart", strings.ReplaceAll(counterSource("count"), "Counter", "OldCounter"))
+				f.write(t, "lib/widgets.dart", counterSource("count"))
+			},
+			want: []string{"app.js", "lib/counter.js", "lib/widgets.js"},
+		},
+		{
+			name: "config",
+			change: func(t *testing.T, f *cacheFixture) {
+				f.write(t, "vortex.config.yml", cacheFixtureFiles["vortex.config.yml"]+"# edited\n")
+			},
+			want: all,
+		},
+		{
+			name:   "compiler",
+			change: func(t *testing.T, f *cacheFixture) { f.stamp = "other" },
+			want:   all,
+		},
+		{
+			name:   "minify",
+			change: func(t *testing.T, f *cacheFixture) { f.minify = true },
+			want:   all,
+		},
+		{
+			name:   "source maps",
+			change: func(t *testing.T, f *cacheFixture) { f.sourceMaps = true },
+			want:   all,
+		},
+		{
+			name:   "output directory",
+			setup:  func(t *testing.T, f *cacheFixture) { f.sourceMaps = true },
+			change: func(t *testing.T, f *cacheFixture) { f.out = filepath.Join(f.out, "deep") },
+			want:   all,
+		},
+		{
+			name:   "package",
+			change: func(t *testing.T, f *cacheFixture) { f.write(t, "pubspec.yaml", "name: other\n") },
+			want:   all,
+		},
+		{
+			// lib/main.dart compiles to lib/main.js until main.dart is
+			// removed, then it is the entry and compiles to app.js
+			name: "entry",
+			setup: func(t *testing.T, f *cacheFixture) {
+				f.write(t, "lib/main.dart", strings.ReplaceAll(cacheFixtureFiles["main.dart"], "App", "Home"))
+			},
+			change: func(t *testing.T, f *cacheFixture) {
+				if err := os.Remove(filepath.Join(f.dir, "main.dart")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"app.js"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCacheFixture(t)
+			if tt.setup != nil {
+				tt.setup(t, f)
+			}
+			f.build(t, true)
+			if tt.change != nil {
+				tt.change(t, f)
+			}
+			generated, _ := f.build(t, true)
+			if strings.Join(generated, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("regenerated %q, want %q", generated, tt.want)
+			}
+
+			// The output taken from the cache must be what compiling every
+			// file produces
+			cached := f.outputs(t)
+			f.build(t, false)
+			fresh := f.outputs(t)
+			for path, content := range fresh {
+				if cached[path] != content {
+					t.Errorf("%s differs from the output of a build without the cache:\n%s\nwant\n%s", path, cached[path], content)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildCacheReplaysWarnings(t *testing.T) {
+	f := newCacheFixture(t)
+	const warning = "warning[G001]: Placeholder is not supported by the runtime and was left out"
+	_, stderr := f.build(t, true)
+	if !strings.Contains(stderr, warning) {
+		t.Fatalf("first build printed %q, want the warning %q", stderr, warning)
+	}
+	generated, replayed := f.build(t, true)
+	if len(generated) != 0 {
+		t.Errorf("second build regenerated %q, want nothing", generated)
+	}
+	if replayed != stderr {
+		t.Errorf("build from the cache printed\n%s\nwant\n%s", replayed, stderr)
+	}
+}
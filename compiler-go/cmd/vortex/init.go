@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"compiler-go/internal/templates"
+)
+
+var initCommand = &command{
+	name:    "init",
+	args:    "[dir]",
+	summary: "Create vortex.config.yml and page templates for a project",
+	help: `Init scaffolds a project in dir (default "."): it writes a default
+vortex.config.yml and copies the default page templates, index.html and
+styles.css, into dir/templates for customizing. Templates deleted from
+there fall back to the defaults built into vortex. Existing files are
+left untouched unless -force is given.`,
+	flags: func(fs *flag.FlagSet) {
+		fs.Bool("force", false, "Overwrite existing files")
+	},
+	run: runInit,
+}
+
+// defaultConfig is the vortex.config.yml written by init
+const defaultConfig = `compiler:
+  # Directory the build writes to
+  outputDir: dist
+  useFlutterWind: false
+  # Widgets removed before code generation, such as debug-only wrappers
+  stripWidgets: []
+  # Page templates overriding the built-in defaults
+  templatesDir: templates
+`
+
+func runInit(fs *flag.FlagSet) int {
+	dir := "."
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		dir = fs.Arg(0)
+	default:
+		return usageError(fs, "too many arguments")
+	}
+	force := fs.Lookup("force").Value.String() == "true"
+
+	names := []string{"vortex.config.yml"}
+	files := map[string][]byte{"vortex.config.yml": []byte(defaultConfig)}
+	for _, name := range templates.Names {
+		content, err := templates.Default(name)
+		if err != nil {
+			return fail(fs, err)
+		}
+		path := filepath.Join("templates", name)
+		names = append(names, path)
+		files[path] = content
+	}
+
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil && !force {
+			fmt.Printf("Skipped: %s already exists\n", path)
+			continue
+		}
+		if err := writeFile(path, files[name]); err != nil {
+			return fail(fs, err)
+		}
+		fmt.Printf("Created: %s\n", path)
+	}
+	return exitOK
+}
@@ -1,146 +1,156 @@
+// Command vortex compiles Flutter projects written in Dart to HTML, CSS
+// and JavaScript.
+//
+// Usage:
+//
+//	vortex <command> [flags] [arguments]
+//
+// Run `vortex help` for the list of commands.
 package main
 
 import (
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
 
-	"compiler-go/internal/config"
-	"compiler-go/internal/generator"
-	"compiler-go/internal/parser"
+	"compiler-go/internal/diag"
 )
 
-func main() {
-	// Parse command line flags
-	sourceDir := flag.String("source", "", "Source directory containing Flutter project")
-	outputDir := flag.String("output", "dist", "Output directory for generated files")
-	flag.Parse()
-
-	// Validate source directory
-	if *sourceDir == "" {
-		fmt.Println("Error: source directory is required")
-		flag.Usage()
-		os.Exit(1)
-	}
+// Exit codes shared by every command
+const (
+	exitOK    = 0 // the command succeeded
+	exitError = 1 // the command failed, e.g. the source has errors
+	exitUsage = 2 // the command line is invalid
+)
 
-	// Load config from project root
-	cfg, err := config.LoadConfig(*sourceDir)
-	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		os.Exit(1)
-	}
+// command is a vortex subcommand
+type command struct {
+	name    string
+	args    string                 // argument synopsis for the usage line
+	summary string                 // one line, shown by `vortex help`
+	help    string                 // shown by `vortex help <command>`
+	flags   func(fs *flag.FlagSet) // declares the command's flags
+	run     func(fs *flag.FlagSet) int
+}
 
-	// Use output directory from config if specified
-	if cfg.Compiler.OutputDir != "" {
-		*outputDir = cfg.Compiler.OutputDir
-	}
+// commands lists the subcommands in the order help shows them
+var commands []*command
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(*outputDir, 0755); err != nil {
-		fmt.Printf("Error creating output directory: %v\n", err)
-		os.Exit(1)
-	}
+func init() {
+	commands = []*command{buildCommand, watchCommand, serveCommand, checkCommand, astCommand, initCommand, cleanCommand}
+}
 
-	// Initialize parser and generator
-	parser := parser.NewParser()
-	jsGenerator := generator.NewJSGenerator()
-	jsGenerator.SetSourceDir(*sourceDir)
-
-	// Process main.dart first
-	mainDartPath := filepath.Join(*sourceDir, "main.dart")
-	if _, err := os.Stat(mainDartPath); err == nil {
-		// Read main.dart
-		source, err := os.ReadFile(mainDartPath)
-		if err != nil {
-			fmt.Printf("Error reading main.dart: %v\n", err)
-			os.Exit(1)
-		}
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-		// Parse main.dart
-		widgetTree, err := parser.Parse(string(source))
-		if err != nil {
-			fmt.Printf("Error parsing main.dart: %v\n", err)
-			os.Exit(1)
+// run executes the command named by args[0] and returns the exit code
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd := lookup(args[1]); cmd != nil {
+				newFlagSet(cmd).Usage()
+				return exitOK
+			}
 		}
+		usage()
+		return exitOK
+	}
 
-		// Generate JavaScript code
-		jsCode, err := jsGenerator.Generate(widgetTree)
-		if err != nil {
-			fmt.Printf("Error generating code for main.dart: %v\n", err)
-			os.Exit(1)
+	cmd := lookup(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "vortex: unknown command %q\n", name)
+		fmt.Fprintf(os.Stderr, "Run 'vortex help' for usage.\n")
+		return exitUsage
+	}
+	fs := newFlagSet(cmd)
+	if err := fs.Parse(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
 		}
+		return exitUsage
+	}
+	return cmd.run(fs)
+}
 
-		// Write main.js
-		mainJsPath := filepath.Join(*outputDir, "main.js")
-		if err := os.WriteFile(mainJsPath, []byte(jsCode), 0644); err != nil {
-			fmt.Printf("Error writing main.js: %v\n", err)
-			os.Exit(1)
+func lookup(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
 		}
-		fmt.Printf("Generated: %s\n", mainJsPath)
 	}
+	return nil
+}
 
-	// Process lib directory
-	libDir := filepath.Join(*sourceDir, "lib")
-	if _, err := os.Stat(libDir); err != nil {
-		fmt.Printf("Warning: lib directory not found: %v\n", err)
-		return
+// newFlagSet returns the flag set of cmd with the usage text every
+// command shares
+func newFlagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	if cmd.flags != nil {
+		cmd.flags(fs)
 	}
-
-	// Walk through lib directory
-	err = filepath.Walk(libDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip directories and non-Dart files
-		if info.IsDir() || filepath.Ext(path) != ".dart" {
-			return nil
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: vortex %s [flags] %s\n\n%s\n", cmd.name, cmd.args, cmd.help)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintf(fs.Output(), "\nFlags:\n")
+			fs.PrintDefaults()
 		}
+	}
+	return fs
+}
 
-		// Read source file
-		source, err := os.ReadFile(path)
-		if err != nil {
-			return fmt.Errorf("error reading file %s: %v", path, err)
-		}
+func usage() {
+	fmt.Fprintf(os.Stderr, "Vortex compiles Flutter projects written in Dart to HTML, CSS and JavaScript.\n\n")
+	fmt.Fprintf(os.Stderr, "Usage: vortex <command> [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'vortex help <command>' for details about a command.\n")
+}
 
-		// Parse the file
-		widgetTree, err := parser.Parse(string(source))
-		if err != nil {
-			return fmt.Errorf("error parsing file %s: %v", path, err)
-		}
+// usageError reports a bad command line and returns exitUsage
+func usageError(fs *flag.FlagSet, format string, args ...any) int {
+	fmt.Fprintf(os.Stderr, "vortex %s: %s\n", fs.Name(), fmt.Sprintf(format, args...))
+	fs.Usage()
+	return exitUsage
+}
 
-		// Generate JavaScript code
-		jsCode, err := jsGenerator.Generate(widgetTree)
-		if err != nil {
-			return fmt.Errorf("error generating code for %s: %v", path, err)
-		}
+// fail reports an error and returns exitError
+func fail(fs *flag.FlagSet, err error) int {
+	fmt.Fprintf(os.Stderr, "vortex %s: %v\n", fs.Name(), err)
+	return exitError
+}
 
-		// Create output file path
-		relPath, err := filepath.Rel(libDir, path)
-		if err != nil {
-			return fmt.Errorf("error getting relative path: %v", err)
-		}
-		outputPath := filepath.Join(*outputDir, "lib", relPath[:len(relPath)-5]+".js")
+// verbose enables progress output from builds, which are otherwise
+// silent unless something goes wrong
+var verbose bool
 
-		// Create output directory if it doesn't exist
-		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-			return fmt.Errorf("error creating output directory: %v", err)
-		}
+// logf prints progress output when verbose is set
+func logf(format string, args ...any) {
+	if verbose {
+		fmt.Printf(format, args...)
+	}
+}
 
-		// Write generated code to file
-		if err := os.WriteFile(outputPath, []byte(jsCode), 0644); err != nil {
-			return fmt.Errorf("error writing file %s: %v", outputPath, err)
+// errorHook, if set, is called with each error diagnostic printed by
+// printDiagnostics, formatted with its source snippet
+var errorHook func(formatted string)
+
+// printDiagnostics writes diagnostics with source snippets to stderr
+func printDiagnostics(diags diag.List, src string) {
+	for _, d := range diags {
+		formatted := diag.Format(d, src)
+		fmt.Fprint(os.Stderr, formatted)
+		if errorHook != nil && d.Severity == diag.Error {
+			errorHook(formatted)
 		}
-
-		fmt.Printf("Generated: %s\n", outputPath)
-		return nil
-	})
-
-	if err != nil {
-		fmt.Printf("Error processing files: %v\n", err)
-		os.Exit(1)
 	}
-
-	fmt.Println("Compilation completed successfully!")
 }
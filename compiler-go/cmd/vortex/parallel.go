@@ -0,0 +1,29 @@
+package main
+
+import "sync"
+
+// parallel calls f(worker, i) for each i from 0 to n-1 on up to jobs
+// goroutines and waits for the calls to return. worker identifies the
+// goroutine making the call, from 0 to jobs-1, so f can keep state per
+// worker.
+func parallel(n, jobs int, f func(worker, i int)) {
+	if jobs > n {
+		jobs = n
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < jobs; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := range next {
+				f(worker, i)
+			}
+		}(worker)
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"compiler-go/internal/config"
+	"compiler-go/internal/diag"
+	"compiler-go/internal/generator"
+	"compiler-go/internal/minify"
+	"compiler-go/internal/parser"
+	"compiler-go/internal/sourcemap"
+	"compiler-go/internal/templates"
+	"compiler-go/pkg/ast"
+	"compiler-go/pkg/pass"
+)
+
+// errDiagnostics is returned when compilation fails with errors that have
+// already been reported as diagnostics
+var errDiagnostics = errors.New("compilation failed")
+
+// project is a set of Dart files compiled together: a project directory
+// with main.dart and lib/, or a single Dart file
+type project struct {
+	sourceDir  string // directory the config is loaded from
+	outputDir  string
+	single     bool // the project is a single Dart file
+	jobs       int  // number of files compiled in parallel
+	minify     bool // the output is minified and the runtime pruned
+	sourceMaps bool // each generated file has a source map
+	config     *config.VortexConfig
+	pkg        string // name of the app's package, for package: imports
+	files      []*sourceFile
+	modules    map[string]string      // output path of each file, by path
+	symbols    *generator.SymbolTable // widgets declared by files, set by parse
+}
+
+// sourceFile is a Dart file to compile and the JavaScript file it
+// compiles to
+type sourceFile struct {
+	path       string
+	outputPath string
+	entry      bool // the file compiles to app.js, which the page loads
+	source     string
+	read       bool // source has been read
+	unit       *ast.CompilationUnit
+	diags      diag.List // diagnostics of the last parse and generation
+	err        error     // failure other than an error diagnostic
+}
+
+// outputFlag declares the -o flag shared by commands that build
+func outputFlag(fs *flag.FlagSet) {
+	fs.String("o", "", "Output directory (default: compiler.outputDir from the config, or dist)")
+}
+
+// jobsFlag declares the -j flag shared by commands that compile
+func jobsFlag(fs *flag.FlagSet) {
+	fs.Int("j", runtime.NumCPU(), "Number of files to compile in parallel")
+}
+
+// sourceMapFlag declares the -source-map flag shared by commands that
+// build
+func sourceMapFlag(fs *flag.FlagSet) {
+	fs.Bool("source-map", false, "Write a source map, <file>.js.map, pointing each generated file back to its Dart source")
+}
+
+// loadProject loads the config of the project at path, which is a project
+// directory or a single Dart file, and finds its source files. The output
+// directory is outputDir if set, else the one from the config. Up to jobs
+// files are compiled in parallel.
+func loadProject(path, outputDir string, jobs int) (*project, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+	p := &project{sourceDir: path, jobs: jobs}
+	if !info.IsDir() {
+		if filepath.Ext(path) != ".dart" {
+			return nil, fmt.Errorf("%s is not a Dart file or project directory", path)
+		}
+		p.sourceDir = filepath.Dir(path)
+		p.single = true
+	}
+
+	p.config, err = config.LoadConfig(p.sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %v", err)
+	}
+	p.outputDir = outputDir
+	if p.outputDir == "" {
+		p.outputDir = p.config.Compiler.OutputDir
+	}
+	if p.outputDir == "" {
+		p.outputDir = "dist"
+	}
+
+	if !p.single {
+		p.files, err = collectSourceFiles(path, p.outputDir)
+		if err != nil {
+			return nil, err
+		}
+		if len(p.files) == 0 {
+			return nil, fmt.Errorf("no Dart files found in %s", path)
+		}
+	} else {
+		p.files = []*sourceFile{{path: path, outputPath: filepath.Join(p.outputDir, "app.js"), entry: true}}
+	}
+	p.pkg = config.PackageName(p.sourceDir)
+	p.modules = make(map[string]string, len(p.files))
+	for _, file := range p.files {
+		p.modules[file.path] = file.outputPath
+	}
+	return p, nil
+}
+
+// compile parses every file, runs the configured passes and generates
+// JavaScript, writing the output when write is set. Files are compiled
+// in parallel and their diagnostics printed in order. If any file has an
+// error, nothing is written and compile returns errDiagnostics, or the
+// errors joined if some are not diagnostics.
+func (p *project) compile(write bool) error {
+	if err := p.parse(nil); err != nil {
+		return err
+	}
+	if _, err := p.generate(p.files, write); err != nil {
+		return err
+	}
+	if write {
+		return p.writeAssets(p.runtimeWidgets(nil))
+	}
+	return nil
+}
+
+// parse parses every file and runs the configured passes on it, except
+// that files found in reuse keep the unit parsed by an earlier build.
+// Every file is parsed before any is generated, so that widgets declared
+// in one file resolve when used in another.
+func (p *project) parse(reuse map[string]*sourceFile) error {
+	var files []*sourceFile
+	for _, file := range p.files {
+		if prev, ok := reuse[file.path]; ok {
+			file.source, file.read, file.unit = prev.source, true, prev.unit
+		} else {
+			files = append(files, file)
+		}
+	}
+	p.parseFiles(files)
+	for _, file := range files {
+		printDiagnostics(file.diags, file.source)
+	}
+	if err := joinErrors(files); err != nil {
+		return err
+	}
+	p.addSymbols(p.files)
+	return nil
+}
+
+// parseFiles reads files that have not been read, then parses them and
+// runs the configured passes on them in parallel. Each file's
+// diagnostics and error are recorded on it.
+func (p *project) parseFiles(files []*sourceFile) {
+	passes := pass.NewPipeline()
+	if len(p.config.Compiler.StripWidgets) > 0 {
+		passes.Add(pass.StripWidgets(p.config.Compiler.StripWidgets...))
+	}
+
+	parallel(len(files), p.jobs, func(_, i int) {
+		file := files[i]
+		file.unit, file.diags, file.err = nil, nil, nil
+		if err := file.readSource(); err != nil {
+			file.err = err
+			return
+		}
+		unit, diags := parser.NewParser().ParseUnit(file.path, file.source)
+		file.diags = diags
+		if diags.HasErrors() {
+			return
+		}
+		if err := passes.Run(unit); err != nil {
+			file.err = fmt.Errorf("error processing file %s: %v", file.path, err)
+			return
+		}
+		file.unit = unit
+	})
+}
+
+// addSymbols builds the project's symbol table from the units of files.
+// Widgets are added in file order so that the first declaration of a
+// duplicate wins.
+func (p *project) addSymbols(files []*sourceFile) {
+	p.symbols = generator.NewSymbolTable()
+	for _, file := range files {
+		diags := p.symbols.AddUnit(file.unit)
+		printDiagnostics(diags, file.source)
+		file.diags = append(file.diags, diags...)
+	}
+}
+
+// readSource reads the file's source unless it has been read
+func (f *sourceFile) readSource() error {
+	if f.read {
+		return nil
+	}
+	source, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("error reading file %s: %v", f.path, err)
+	}
+	f.source, f.read = string(source), true
+	return nil
+}
+
+// generate generates JavaScript for files, which must have been parsed,
+// and writes it to their output paths when write is set. It returns the
+// output of each file, minified if the project is.
+func (p *project) generate(files []*sourceFile, write bool) ([]output, error) {
+	outputs := make([]output, len(files))
+	err := p.eachModule(files, func(jsGenerator *generator.JSGenerator, i int) error {
+		code, err := jsGenerator.GenerateModule(files[i].unit, p.moduleOptions(files[i]))
+		if err == nil && p.minify {
+			code, err = minify.JS(code)
+		}
+		if err != nil {
+			return err
+		}
+		outputs[i], err = p.output(code, files[i].outputPath)
+		return err
+	})
+	if err != nil || !write {
+		return outputs, err
+	}
+
+	parallel(len(files), p.jobs, func(_, i int) {
+		files[i].err = outputs[i].write(files[i].outputPath)
+	})
+	for _, file := range files {
+		if file.err == nil {
+			logf("Generated: %s\n", file.outputPath)
+		}
+	}
+	return outputs, joinErrors(files)
+}
+
+// output is the code generated for a file and its source map, which is
+// nil unless the project has source maps
+type output struct {
+	code      string
+	sourceMap []byte
+}
+
+// output returns the output of code generated for path. If the project
+// has source maps, the marks in code become the source map, path.map,
+// which the code then names in a sourceMappingURL comment.
+func (p *project) output(code, path string) (output, error) {
+	if !p.sourceMaps {
+		return output{code: code}, nil
+	}
+	code, mappings := sourcemap.Extract(code)
+	m := sourcemap.New(filepath.Base(path), mappings, func(source string) (string, string, bool) {
+		for _, file := range p.files {
+			if file.path == source && file.read {
+				rel, err := filepath.Rel(filepath.Dir(path), source)
+				if err != nil {
+					rel = source
+				}
+				return filepath.ToSlash(rel), file.source, true
+			}
+		}
+		return "", "", false
+	})
+	data, err := json.Marshal(m)
+	if err != nil {
+		return output{}, err
+	}
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	code += "//# sourceMappingURL=" + url.PathEscape(filepath.Base(path)) + ".map\n"
+	return output{code: code, sourceMap: data}, nil
+}
+
+// write writes the output to path and its source map, if any, next to it
+func (o output) write(path string) error {
+	if err := writeFile(path, []byte(o.code)); err != nil {
+		return err
+	}
+	if o.sourceMap != nil {
+		return writeFile(path+".map", o.sourceMap)
+	}
+	return nil
+}
+
+// eachModule calls gen for each of files in parallel, then prints the
+// diagnostics of each file in order. Each worker has its own generator,
+// since a generator holds the state of the file it is generating.
+func (p *project) eachModule(files []*sourceFile, gen func(jsGenerator *generator.JSGenerator, i int) error) error {
+	generators := make([]*generator.JSGenerator, p.jobs)
+	diags := make([]diag.List, len(files))
+	parallel(len(files), p.jobs, func(worker, i int) {
+		jsGenerator := generators[worker]
+		if jsGenerator == nil {
+			jsGenerator = generator.NewJSGenerator()
+			jsGenerator.SetSourceDir(p.sourceDir)
+			jsGenerator.SetConfig(p.config)
+			jsGenerator.SetSymbols(p.symbols)
+			generators[worker] = jsGenerator
+		}
+		if err := gen(jsGenerator, i); err != nil {
+			files[i].err = fmt.Errorf("error generating code for %s: %v", files[i].path, err)
+			return
+		}
+		diags[i] = jsGenerator.Diagnostics()
+	})
+	for i, file := range files {
+		printDiagnostics(diags[i], file.source)
+		file.diags = append(file.diags, diags[i]...)
+	}
+	return joinErrors(files)
+}
+
+// moduleOptions returns the options file is generated with
+func (p *project) moduleOptions(file *sourceFile) generator.ModuleOptions {
+	return generator.ModuleOptions{
+		Path:      file.outputPath,
+		Runtime:   filepath.Join(p.outputDir, generator.RuntimeModule),
+		Entry:     file.entry,
+		Package:   p.pkg,
+		Modules:   p.modules,
+		SourceMap: p.sourceMaps,
+	}
+}
+
+// joinErrors returns the errors of files joined, or errDiagnostics if
+// there are none but some diagnostics are errors
+func joinErrors(files []*sourceFile) error {
+	var errs []error
+	failed := false
+	for _, file := range files {
+		if file.err != nil {
+			errs = append(errs, file.err)
+		}
+		failed = failed || file.diags.HasErrors()
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if failed {
+		return errDiagnostics
+	}
+	return nil
+}
+
+// writeAssets writes the files shared by every build to the output
+// directory: the runtime module and the page templates. The runtime keeps
+// only the widget methods for widgets unless widgets is nil, and is
+// minified if the project is.
+func (p *project) writeAssets(widgets map[string]bool) error {
+	jsGenerator := generator.NewJSGenerator()
+	jsGenerator.SetConfig(p.config)
+	code, err := jsGenerator.GenerateRuntime(widgets)
+	if err != nil {
+		return err
+	}
+	if p.minify {
+		if code, err = minify.JS(code); err != nil {
+			return fmt.Errorf("error minifying %s: %v", generator.RuntimeModule, err)
+		}
+	}
+	runtimePath := filepath.Join(p.outputDir, generator.RuntimeModule)
+	if err := writeFile(runtimePath, []byte(code)); err != nil {
+		return err
+	}
+	logf("Generated: %s\n", runtimePath)
+	return p.copyTemplates("app.js")
+}
+
+// runtimeWidgets returns the runtime widgets that the files construct if
+// the project is minified, and nil otherwise. Files that have not been
+// parsed are taken from their entries in cached.
+func (p *project) runtimeWidgets(cached map[*sourceFile]*cacheEntry) map[string]bool {
+	if !p.minify {
+		return nil
+	}
+	var units []*ast.CompilationUnit
+	widgets := make(map[string]bool)
+	for _, file := range p.files {
+		if file.unit != nil {
+			units = append(units, file.unit)
+			continue
+		}
+		for name := range cached[file].Uses {
+			if generator.IsBuiltin(name) {
+				widgets[name] = true
+			}
+		}
+	}
+	for name := range generator.UsedWidgets(units) {
+		widgets[name] = true
+	}
+	return widgets
+}
+
+// appScript matches the script tag attribute by which index.html loads
+// app.js
+var appScript = regexp.MustCompile(`(src\s*=\s*["'])(?:\./)?app\.js(["'])`)
+
+// copyTemplates copies the page templates to the output directory,
+// taking each from the project's templates directory if it has one and
+// otherwise using the embedded default. If script is not app.js, the
+// copy of index.html loads script instead.
+func (p *project) copyTemplates(script string) error {
+	for _, name := range templates.Names {
+		content, err := templates.Read(p.config.TemplatesDir(), name)
+		if err != nil {
+			return fmt.Errorf("error reading template file: %v", err)
+		}
+		if name == "index.html" && script != "app.js" {
+			if !appScript.Match(content) {
+				return fmt.Errorf("index.html has no script tag loading app.js to point at %s", script)
+			}
+			content = appScript.ReplaceAll(content, []byte("${1}"+script+"${2}"))
+		}
+		outputPath := filepath.Join(p.outputDir, name)
+		if err := writeFile(outputPath, content); err != nil {
+			return err
+		}
+		logf("Generated: %s\n", outputPath)
+	}
+	return nil
+}
+
+// writeFile writes a file, creating its directory if needed
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %v", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("error writing file %s: %v", path, err)
+	}
+	return nil
+}
+
+// collectSourceFiles returns main.dart followed by every Dart file in
+// the lib directory. The entry file, main.dart or else lib/main.dart,
+// compiles to app.js, which the page template loads, and the files in lib
+// to the same paths under lib in the output directory.
+func collectSourceFiles(sourceDir, outputDir string) ([]*sourceFile, error) {
+	var files []*sourceFile
+
+	mainDartPath := filepath.Join(sourceDir, "main.dart")
+	hasMain := false
+	if _, err := os.Stat(mainDartPath); err == nil {
+		files = append(files, &sourceFile{path: mainDartPath, outputPath: filepath.Join(outputDir, "app.js"), entry: true})
+		hasMain = true
+	}
+
+	libDir := filepath.Join(sourceDir, "lib")
+	if _, err := os.Stat(libDir); err != nil {
+		logf("Warning: lib directory not found: %v\n", err)
+		return files, nil
+	}
+
+	// Walk through lib directory
+	err := filepath.Walk(libDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip the output directory and non-Dart files
+		if info.IsDir() {
+			if path == outputDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".dart" {
+			return nil
+		}
+
+		// Create output file path
+		relPath, err := filepath.Rel(libDir, path)
+		if err != nil {
+			return fmt.Errorf("error getting relative path: %v", err)
+		}
+		outputPath := filepath.Join(outputDir, "lib", strings.TrimSuffix(relPath, ".dart")+".js")
+		if relPath == "main.dart" && !hasMain {
+			// lib/main.dart is the entry of a standard Flutter project
+			file := &sourceFile{path: path, outputPath: filepath.Join(outputDir, "app.js"), entry: true}
+			files = append([]*sourceFile{file}, files...)
+			return nil
+		}
+		files = append(files, &sourceFile{path: path, outputPath: outputPath})
+		return nil
+	})
+	return files, err
+}
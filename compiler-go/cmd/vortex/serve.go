@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var serveCommand = &command{
+	name:    "serve",
+	args:    "[project-dir | file.dart]",
+	summary: "Build and serve the output with live reload",
+	help: `Serve builds a project, or a single Dart file, and serves the output
+directory over HTTP. Paths without a file, such as deep links handled by
+the app's router, fall back to index.html. Like watch, it rebuilds
+whenever a source file changes: open pages reload after each successful
+rebuild and show compile errors as an overlay.`,
+	flags: func(fs *flag.FlagSet) {
+		outputFlag(fs)
+		jobsFlag(fs)
+		sourceMapFlag(fs)
+		fs.BoolVar(&verbose, "v", false, "Report each generated file")
+		fs.String("addr", "localhost:8080", "Address to listen on")
+		fs.Duration("interval", 500*time.Millisecond, "How often to check for changes")
+	},
+	run: runServe,
+}
+
+// Paths served by the dev server itself rather than from the output
+// directory
+const (
+	liveReloadScriptPath = "/__vortex/livereload.js"
+	liveReloadEventsPath = "/__vortex/events"
+)
+
+func runServe(fs *flag.FlagSet) int {
+	p, code := projectArg(fs)
+	if p == nil {
+		return code
+	}
+	addr := fs.Lookup("addr").Value.String()
+	interval := fs.Lookup("interval").Value.(flag.Getter).Get().(time.Duration)
+
+	// Errors are collected during each build and sent to the browser
+	// when it fails
+	hub := newReloadHub()
+	var errors []string
+	errorHook = func(formatted string) { errors = append(errors, formatted) }
+	go watch(fs, p, interval, func(err error) {
+		if err == nil {
+			hub.broadcast(reloadEvent{name: "reload"})
+		} else {
+			message := strings.Join(errors, "\n")
+			if len(errors) == 0 {
+				message = err.Error()
+			}
+			hub.broadcast(reloadEvent{name: "compile-error", data: message})
+		}
+		errors = nil
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(liveReloadEventsPath, hub)
+	mux.HandleFunc(liveReloadScriptPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, liveReloadScript)
+	})
+	mux.Handle("/", &devHandler{dir: p.outputDir})
+
+	fmt.Printf("Serving %s on http://%s\n", p.outputDir, addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		return fail(fs, err)
+	}
+	return exitOK
+}
+
+// devHandler serves the output directory. Requests for paths without a
+// file extension that match no file get index.html, so that routes of a
+// single-page app can be loaded directly, and every HTML page is served
+// with the live reload client.
+type devHandler struct {
+	dir string
+}
+
+func (h *devHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	urlPath := path.Clean("/" + r.URL.Path)
+	file := filepath.Join(h.dir, filepath.FromSlash(urlPath))
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		file = filepath.Join(file, "index.html")
+	}
+	fallback := false
+	if _, err := os.Stat(file); err != nil {
+		if path.Ext(urlPath) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		file = filepath.Join(h.dir, "index.html")
+		fallback = true
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if filepath.Ext(file) != ".html" {
+		http.ServeFile(w, r, file)
+		return
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if fallback {
+		// Resolve the page's relative URLs against the root rather than
+		// the deep link
+		if i := bytes.Index(content, []byte("<head>")); i >= 0 {
+			content = insertAt(content, i+len("<head>"), `<base href="/">`)
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	i := bytes.LastIndex(content, []byte("</body>"))
+	if i < 0 {
+		i = len(content)
+	}
+	w.Write(insertAt(content, i, `<script src="`+liveReloadScriptPath+`"></script>`))
+}
+
+// insertAt returns a copy of page with tag inserted at offset i
+func insertAt(page []byte, i int, tag string) []byte {
+	return append(append(append([]byte{}, page[:i]...), tag...), page[i:]...)
+}
+
+// reloadEvent is a server-sent event for the live reload client
+type reloadEvent struct {
+	name string // reload or compile-error
+	data string
+}
+
+// reloadHub sends reload events to the connected live reload clients
+type reloadHub struct {
+	mu      sync.Mutex
+	clients map[chan reloadEvent]bool
+	last    reloadEvent // the last event, sent to clients as they connect
+}
+
+func newReloadHub() *reloadHub {
+	return &reloadHub{clients: make(map[chan reloadEvent]bool)}
+}
+
+func (h *reloadHub) broadcast(event reloadEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = event
+	for client := range h.clients {
+		select {
+		case client <- event:
+		default:
+			// The client is behind; it only needs the latest event
+		}
+	}
+}
+
+// ServeHTTP streams events to a client until it disconnects
+func (h *reloadHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+
+	client := make(chan reloadEvent, 1)
+	h.mu.Lock()
+	h.clients[client] = true
+	if h.last.name == "compile-error" {
+		// A page loaded while the build is broken shows the error
+		client <- h.last
+	}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, client)
+		h.mu.Unlock()
+	}()
+
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-client:
+			data, _ := json.Marshal(event.data)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// liveReloadScript is the live reload client injected into every page.
+// It reloads the page after a successful rebuild and shows compile
+// errors in an overlay until the next one.
+const liveReloadScript = `(function () {
+  var overlay = null;
+
+  function hideOverlay() {
+    if (overlay) {
+      overlay.remove();
+      overlay = null;
+    }
+  }
+
+  function showOverlay(message) {
+    hideOverlay();
+    overlay = document.createElement('div');
+    overlay.style.cssText = 'position:fixed;inset:0;z-index:2147483647;overflow:auto;' +
+      'padding:24px;background:rgba(24,24,24,0.95);color:#f0f0f0;' +
+      'font:13px/1.5 Menlo,Consolas,monospace;';
+    var title = document.createElement('div');
+    title.textContent = 'Vortex: compile failed (click to dismiss)';
+    title.style.cssText = 'color:#ff6b6b;font-weight:bold;margin-bottom:12px;';
+    var pre = document.createElement('pre');
+    pre.textContent = message;
+    pre.style.cssText = 'margin:0;white-space:pre-wrap;';
+    overlay.appendChild(title);
+    overlay.appendChild(pre);
+    overlay.addEventListener('click', hideOverlay);
+    document.body.appendChild(overlay);
+  }
+
+  var source = new EventSource('` + liveReloadEventsPath + `');
+  source.addEventListener('reload', function () {
+    location.reload();
+  });
+  source.addEventListener('compile-error', function (e) {
+    showOverlay(JSON.parse(e.data));
+  });
+})();
+`
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"compiler-go/internal/templates"
+	"compiler-go/pkg/ast"
+)
+
+var watchCommand = &command{
+	name:    "watch",
+	args:    "[project-dir | file.dart]",
+	summary: "Build, then rebuild whenever a source file changes",
+	help: `Watch builds a project, or a single Dart file, like build and then polls
+main.dart, lib/**/*.dart, the config and the templates directory for
+changes. Each rebuild parses only the changed files and regenerates them
+along with the files that use widgets they declare, then prints how long
+it took. A change to the config rebuilds everything. Errors are reported
+and watching continues until interrupted.`,
+	flags: func(fs *flag.FlagSet) {
+		outputFlag(fs)
+		jobsFlag(fs)
+		sourceMapFlag(fs)
+		fs.BoolVar(&verbose, "v", false, "Report each generated file")
+		fs.Duration("interval", 500*time.Millisecond, "How often to check for changes")
+	},
+	run: runWatch,
+}
+
+func runWatch(fs *flag.FlagSet) int {
+	p, code := projectArg(fs)
+	if p == nil {
+		return code
+	}
+	interval := fs.Lookup("interval").Value.(flag.Getter).Get().(time.Duration)
+	watch(fs, p, interval, nil)
+	return exitOK
+}
+
+// watcher rebuilds a project as its files change, reusing what it can
+// from the previous build
+type watcher struct {
+	fs        *flag.FlagSet
+	path      string   // project argument
+	outputDir string   // -o flag, empty to use the config
+	jobs      int      // -j flag
+	p         *project // project of the last build
+	ok        bool     // the last build succeeded
+}
+
+// watch builds p and rebuilds it whenever its inputs change, calling
+// built after each build. The project is reloaded before each rebuild so
+// that added and removed files are picked up. It never returns.
+func watch(fs *flag.FlagSet, p *project, interval time.Duration, built func(err error)) {
+	w := &watcher{fs: fs, path: projectPath(fs), p: p, jobs: p.jobs}
+	if f := fs.Lookup("o"); f != nil {
+		w.outputDir = f.Value.String()
+	}
+
+	rebuild := func(changed []string) {
+		err := w.build(changed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "vortex %s: %v\n", fs.Name(), err)
+		}
+		if built != nil {
+			built(err)
+		}
+	}
+
+	rebuild(nil)
+	last := snapshot(w.p)
+	for {
+		time.Sleep(interval)
+		current := snapshot(w.p)
+		if changed := changedFiles(last, current); len(changed) > 0 {
+			last = current
+			rebuild(changed)
+		}
+	}
+}
+
+// build rebuilds the project after the given files changed, or from
+// scratch if changed is nil, the last build failed or the config changed
+func (w *watcher) build(changed []string) error {
+	start := time.Now()
+	prev := w.p
+	p, err := loadProject(w.path, w.outputDir, w.jobs)
+	if err != nil {
+		w.ok = false
+		return err
+	}
+	p.sourceMaps = prev.sourceMaps
+	w.p = p
+
+	full := changed == nil || !w.ok
+	changedSet := make(map[string]bool, len(changed))
+	templatesChanged := false
+	for _, path := range changed {
+		changedSet[path] = true
+		switch {
+		case path == prev.config.Path || path == p.config.Path:
+			full = true
+		case filepath.Ext(path) != ".dart":
+			templatesChanged = true
+		}
+	}
+
+	var reuse map[string]*sourceFile
+	if !full {
+		reuse = make(map[string]*sourceFile)
+		for _, file := range prev.files {
+			if file.unit != nil && !changedSet[file.path] {
+				reuse[file.path] = file
+			}
+		}
+	}
+	w.ok = false
+	err = p.parse(reuse)
+	parsed := time.Now()
+	if err != nil {
+		return err
+	}
+
+	dirty := p.files
+	dependents := 0
+	if !full {
+		dirty, dependents = affectedFiles(prev, p, changedSet)
+	}
+	if _, err := p.generate(dirty, true); err != nil {
+		return err
+	}
+	if full || templatesChanged {
+		if err := p.writeAssets(nil); err != nil {
+			return err
+		}
+	}
+	if !full {
+		removeStaleOutput(prev, p)
+	}
+	w.ok = true
+
+	end := time.Now()
+	timings := fmt.Sprintf("parse %v, generate %v", parsed.Sub(start).Round(timingPrecision), end.Sub(parsed).Round(timingPrecision))
+	if full {
+		fmt.Printf("Built %d files in %v (%s)\n", len(p.files), end.Sub(start).Round(timingPrecision), timings)
+	} else {
+		fmt.Printf("Rebuilt %d of %d files in %v (%s; %d changed, %d dependent)\n",
+			len(dirty), len(p.files), end.Sub(start).Round(timingPrecision), timings, len(dirty)-dependents, dependents)
+	}
+	return nil
+}
+
+// timingPrecision is the precision of the timings printed after a build
+const timingPrecision = 100 * time.Microsecond
+
+// affectedFiles returns the files of p to regenerate after the files in
+// changed were modified, added or removed: the changed files themselves
+// and every file that uses a widget declared by one of them, before or
+// after the change. It also returns how many of them are dependents.
+func affectedFiles(prev, p *project, changed map[string]bool) ([]*sourceFile, int) {
+	// Widgets whose declarations may have changed
+	widgets := make(map[string]bool)
+	for _, files := range [][]*sourceFile{prev.files, p.files} {
+		for _, file := range files {
+			if changed[file.path] && file.unit != nil {
+				for _, class := range file.unit.Classes {
+					widgets[class.Name] = true
+				}
+			}
+		}
+	}
+
+	var dirty []*sourceFile
+	dependents := 0
+	for _, file := range p.files {
+		if changed[file.path] {
+			dirty = append(dirty, file)
+		} else if usesAny(file.unit, widgets) {
+			dirty = append(dirty, file)
+			dependents++
+		}
+	}
+	return dirty, dependents
+}
+
+// usesAny reports whether unit constructs any of the given widgets
+func usesAny(unit *ast.CompilationUnit, widgets map[string]bool) bool {
+	if len(widgets) == 0 {
+		return false
+	}
+	found := false
+	ast.Inspect(unit, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.WidgetNode:
+			found = found || widgets[n.Name]
+		case *ast.Call:
+			found = found || (n.Widget != nil && widgets[n.Widget.Name])
+		}
+		return !found
+	})
+	return found
+}
+
+// removeStaleOutput deletes the output of files of prev that are no
+// longer part of p
+func removeStaleOutput(prev, p *project) {
+	current := make(map[string]bool, len(p.files))
+	for _, file := range p.files {
+		current[file.outputPath] = true
+	}
+	for _, file := range prev.files {
+		if !current[file.outputPath] {
+			if err := os.Remove(file.outputPath); err == nil {
+				logf("Removed: %s\n", file.outputPath)
+			}
+		}
+	}
+}
+
+// projectPath returns the project argument of a command
+func projectPath(fs *flag.FlagSet) string {
+	if fs.NArg() > 0 {
+		return fs.Arg(0)
+	}
+	return "."
+}
+
+// snapshot records the modification time of every input of p: main.dart,
+// the Dart files in lib, the config and the templates
+func snapshot(p *project) map[string]time.Time {
+	times := make(map[string]time.Time)
+	record := func(path string) {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			times[path] = info.ModTime()
+		}
+	}
+	record(p.config.Path)
+	if dir := p.config.TemplatesDir(); dir != "" {
+		for _, name := range templates.Names {
+			record(filepath.Join(dir, name))
+		}
+	}
+	if p.single {
+		record(p.files[0].path)
+		return times
+	}
+
+	record(filepath.Join(p.sourceDir, "main.dart"))
+	filepath.Walk(filepath.Join(p.sourceDir, "lib"), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && filepath.Ext(path) == ".dart" {
+			times[path] = info.ModTime()
+		}
+		return nil
+	})
+	return times
+}
+
+// changedFiles returns the files added, removed or modified between two
+// snapshots, sorted
+func changedFiles(before, after map[string]time.Time) []string {
+	var changed []string
+	for path, t := range after {
+		if u, ok := before[path]; !ok || !t.Equal(u) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -12,7 +12,43 @@ type VortexConfig struct {
 	Compiler struct {
 		OutputDir      string `yaml:"outputDir"`
 		UseFlutterWind bool   `yaml:"useFlutterWind"`
+		// StripWidgets names widgets removed before code generation,
+		// such as debug-only wrappers
+		StripWidgets []string `yaml:"stripWidgets"`
+		// TemplatesDir is a directory of page templates overriding the
+		// embedded defaults, relative to the config file
+		TemplatesDir string `yaml:"templatesDir"`
 	} `yaml:"compiler"`
+
+	// Path is the config file the config was loaded from
+	Path string `yaml:"-"`
+}
+
+// TemplatesDir returns the directory of the project's page templates, or
+// "" if the project uses the embedded defaults
+func (c *VortexConfig) TemplatesDir() string {
+	dir := c.Compiler.TemplatesDir
+	if dir == "" || filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(c.Path), dir)
+}
+
+// PackageName returns the name of the Dart package in dir: the name given
+// by its pubspec.yaml, or else the name of dir itself
+func PackageName(dir string) string {
+	var pubspec struct {
+		Name string `yaml:"name"`
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "pubspec.yaml")); err == nil {
+		if yaml.Unmarshal(data, &pubspec) == nil && pubspec.Name != "" {
+			return pubspec.Name
+		}
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Base(dir)
 }
 
 func LoadConfig(sourceDir string) (*VortexConfig, error) {
@@ -35,6 +71,7 @@ func LoadConfig(sourceDir string) (*VortexConfig, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("error parsing config file: %v", err)
 	}
+	config.Path = configPath
 
 	return &config, nil
 }
@@ -0,0 +1,126 @@
+package diag
+
+import (
+	"fmt"
+	"strings"
+
+	"compiler-go/pkg/ast"
+)
+
+// Severity describes how serious a diagnostic is
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// Diagnostic is a single message reported by the parser or a generator
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Span     ast.Span
+}
+
+// Errorf creates an error diagnostic
+func Errorf(span ast.Span, code, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{Severity: Error, Code: code, Message: fmt.Sprintf(format, args...), Span: span}
+}
+
+// Warningf creates a warning diagnostic
+func Warningf(span ast.Span, code, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{Severity: Warning, Code: code, Message: fmt.Sprintf(format, args...), Span: span}
+}
+
+// Error returns the diagnostic as a single compiler-style line, e.g.
+// "lib/main.dart:12:7: error[P004]: expected \")\" but found \";\""
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s: %s[%s]: %s", d.Span, d.Severity, d.Code, d.Message)
+}
+
+// Format returns the diagnostic followed by the offending source line
+// and a caret marking the span. src is the text of the span's file; when
+// it is empty only the header line is returned.
+func Format(d Diagnostic, src string) string {
+	var b strings.Builder
+	b.WriteString(d.Error())
+	b.WriteString("\n")
+
+	if src == "" || !d.Span.IsValid() {
+		return b.String()
+	}
+	lines := strings.Split(src, "\n")
+	if d.Span.Start.Line > len(lines) {
+		return b.String()
+	}
+	line := strings.TrimRight(lines[d.Span.Start.Line-1], "\r")
+
+	// Keep tabs in the padding so the caret lines up with the source
+	col := d.Span.Start.Column - 1
+	if col > len(line) {
+		col = len(line)
+	}
+	var pad strings.Builder
+	for _, c := range line[:col] {
+		if c == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	width := 1
+	if d.Span.End.Line == d.Span.Start.Line && d.Span.End.Column > d.Span.Start.Column {
+		width = d.Span.End.Column - d.Span.Start.Column
+	}
+	if col+width > len(line) && len(line) > col {
+		width = len(line) - col
+	}
+
+	gutter := fmt.Sprintf("%d", d.Span.Start.Line)
+	fmt.Fprintf(&b, " %s | %s\n", gutter, line)
+	fmt.Fprintf(&b, " %s | %s^%s\n", strings.Repeat(" ", len(gutter)), pad.String(), strings.Repeat("~", width-1))
+	return b.String()
+}
+
+// List is an ordered collection of diagnostics
+type List []Diagnostic
+
+// HasErrors reports whether any diagnostic in the list is an error
+func (l List) HasErrors() bool {
+	for _, d := range l {
+		if d.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the list as an error if it contains any errors, or nil
+func (l List) Err() error {
+	if !l.HasErrors() {
+		return nil
+	}
+	return l
+}
+
+// Error joins the diagnostics one per line
+func (l List) Error() string {
+	lines := make([]string, len(l))
+	for i, d := range l {
+		lines[i] = d.Error()
+	}
+	return strings.Join(lines, "\n")
+}
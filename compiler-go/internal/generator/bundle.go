@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"compiler-go/pkg/ast"
+)
+
+// Bundle combines the runtime and modules, which must be ordered so that
+// every module follows the modules it imports and the entry module comes
+// last, into a single script. Each module runs in a scope of its own and
+// returns its classes for the modules after it. The runtime keeps only
+// the widget methods for widgets, and those the kept methods use.
+func (g *JSGenerator) Bundle(modules []*Module, widgets map[string]bool) (string, error) {
+	cfg, err := g.loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("error loading config: %v", err)
+	}
+
+	vars := make(map[string]string, len(modules)) // module scope variable, by file
+	var code strings.Builder
+	fmt.Fprintf(&code, "(() => {\n// Flutter to Web UI Framework\n%s\n", pruneRuntime(runtimeClass(cfg), widgets))
+	for i, m := range modules {
+		fmt.Fprintf(&code, "\n// %s\n", m.File)
+		last := i == len(modules)-1
+		if !last {
+			vars[m.File] = fmt.Sprintf("$module%d", i)
+			fmt.Fprintf(&code, "const %s = ", vars[m.File])
+		}
+		code.WriteString("(() => {\n")
+		for _, imp := range m.Imports {
+			v, ok := vars[imp.File]
+			if !ok {
+				return "", fmt.Errorf("%s imports %s, which is not bundled before it", m.File, imp.File)
+			}
+			fmt.Fprintf(&code, "const { %s } = %s;\n", strings.Join(imp.Names, ", "), v)
+		}
+		code.WriteString(m.Code)
+		if !last {
+			fmt.Fprintf(&code, "\nreturn { %s };\n", strings.Join(m.Classes, ", "))
+		}
+		code.WriteString("})();\n")
+	}
+	code.WriteString("})();\n")
+	return code.String(), nil
+}
+
+// UsedWidgets returns the runtime widgets that units construct
+func UsedWidgets(units []*ast.CompilationUnit) map[string]bool {
+	widgets := make(map[string]bool)
+	for _, unit := range units {
+		ast.Inspect(unit, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.WidgetNode:
+				if IsBuiltin(n.Name) {
+					widgets[n.Name] = true
+				}
+			case *ast.Call:
+				if n.Widget != nil && IsBuiltin(n.Widget.Name) {
+					widgets[n.Widget.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return widgets
+}
+
+// widgetCall matches a call of a widget method in the runtime
+var widgetCall = regexp.MustCompile(`this\.([A-Z]\w*)\(`)
+
+// pruneRuntime removes the methods of the runtime class for widgets not
+// in widgets, unless a method that is kept calls them. Methods start on
+// a line indented by two spaces and end at the next such line holding a
+// closing brace.
+func pruneRuntime(class string, widgets map[string]bool) string {
+	lines := strings.SplitAfter(class, "\n")
+
+	// Find the lines of each widget method
+	type method struct{ start, end int }
+	methods := make(map[string]method)
+	for i := 0; i < len(lines); i++ {
+		name, _, ok := strings.Cut(strings.TrimPrefix(lines[i], "  "), "(")
+		if !strings.HasPrefix(lines[i], "  ") || strings.HasPrefix(lines[i], "   ") || !ok || !IsBuiltin(name) {
+			continue
+		}
+		start := i
+		for i < len(lines) && strings.TrimRight(lines[i], "\n") != "  }" {
+			i++
+		}
+		methods[name] = method{start, i + 1}
+	}
+
+	// Keep the widgets used and those called by code that is kept
+	keep := make(map[string]bool)
+	var use func(text string)
+	use = func(text string) {
+		for _, m := range widgetCall.FindAllStringSubmatch(text, -1) {
+			if name := m[1]; !keep[name] {
+				if method, ok := methods[name]; ok {
+					keep[name] = true
+					use(strings.Join(lines[method.start:method.end], ""))
+				}
+			}
+		}
+	}
+	inMethod := make([]bool, len(lines))
+	for _, method := range methods {
+		for i := method.start; i < method.end; i++ {
+			inMethod[i] = true
+		}
+	}
+	for i, line := range lines {
+		if !inMethod[i] {
+			use(line)
+		}
+	}
+	for name := range widgets {
+		use("this." + name + "(")
+	}
+
+	// Drop the other methods with the blank line before each
+	drop := make([]bool, len(lines))
+	for name, method := range methods {
+		if keep[name] {
+			continue
+		}
+		for i := method.start; i < method.end; i++ {
+			drop[i] = true
+		}
+		if method.start > 0 && strings.TrimSpace(lines[method.start-1]) == "" {
+			drop[method.start-1] = true
+		}
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		if !drop[i] {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// materialShades are the shades of the Material colors, lightest first
+var materialShades = []int{50, 100, 200, 300, 400, 500, 600, 700, 800, 900}
+
+// materialColors holds the shades of each Material color of Colors, whose
+// primary value is shade 500
+var materialColors = map[string][]string{
+	"red":        {"ffebee", "ffcdd2", "ef9a9a", "e57373", "ef5350", "f44336", "e53935", "d32f2f", "c62828", "b71c1c"},
+	"pink":       {"fce4ec", "f8bbd0", "f48fb1", "f06292", "ec407a", "e91e63", "d81b60", "c2185b", "ad1457", "880e4f"},
+	"purple":     {"f3e5f5", "e1bee7", "ce93d8", "ba68c8", "ab47bc", "9c27b0", "8e24aa", "7b1fa2", "6a1b9a", "4a148c"},
+	"deepPurple": {"ede7f6", "d1c4e9", "b39ddb", "9575cd", "7e57c2", "673ab7", "5e35b1", "512da8", "4527a0", "311b92"},
+	"indigo":     {"e8eaf6", "c5cae9", "9fa8da", "7986cb", "5c6bc0", "3f51b5", "3949ab", "303f9f", "283593", "1a237e"},
+	"blue":       {"e3f2fd", "bbdefb", "90caf9", "64b5f6", "42a5f5", "2196f3", "1e88e5", "1976d2", "1565c0", "0d47a1"},
+	"lightBlue":  {"e1f5fe", "b3e5fc", "81d4fa", "4fc3f7", "29b6f6", "03a9f4", "039be5", "0288d1", "0277bd", "01579b"},
+	"cyan":       {"e0f7fa", "b2ebf2", "80deea", "4dd0e1", "26c6da", "00bcd4", "00acc1", "0097a7", "00838f", "006064"},
+	"teal":       {"e0f2f1", "b2dfdb", "80cbc4", "4db6ac", "26a69a", "009688", "00897b", "00796b", "00695c", "004d40"},
+	"green":      {"e8f5e9", "c8e6c9", "a5d6a7", "81c784", "66bb6a", "4caf50", "43a047", "388e3c", "2e7d32", "1b5e20"},
+	"lightGreen": {"f1f8e9", "dcedc8", "c5e1a5", "aed581", "9ccc65", "8bc34a", "7cb342", "689f38", "558b2f", "33691e"},
+	"lime":       {"f9fbe7", "f0f4c3", "e6ee9c", "dce775", "d4e157", "cddc39", "c0ca33", "afb42b", "9e9d24", "827717"},
+	"yellow":     {"fffde7", "fff9c4", "fff59d", "fff176", "ffee58", "ffeb3b", "fdd835", "fbc02d", "f9a825", "f57f17"},
+	"amber":      {"fff8e1", "ffecb3", "ffe082", "ffd54f", "ffca28", "ffc107", "ffb300", "ffa000", "ff8f00", "ff6f00"},
+	"orange":     {"fff3e0", "ffe0b2", "ffcc80", "ffb74d", "ffa726", "ff9800", "fb8c00", "f57c00", "ef6c00", "e65100"},
+	"deepOrange": {"fbe9e7", "ffccbc", "ffab91", "ff8a65", "ff7043", "ff5722", "f4511e", "e64a19", "d84315", "bf360c"},
+	"brown":      {"efebe9", "d7ccc8", "bcaaa4", "a1887f", "8d6e63", "795548", "6d4c41", "5d4037", "4e342e", "3e2723"},
+	"grey":       {"fafafa", "f5f5f5", "eeeeee", "e0e0e0", "bdbdbd", "9e9e9e", "757575", "616161", "424242", "212121"},
+	"blueGrey":   {"eceff1", "cfd8dc", "b0bec5", "90a4ae", "78909c", "607d8b", "546e7a", "455a64", "37474f", "263238"},
+}
+
+// accentShades are the shades of the Material accent colors
+var accentShades = []int{100, 200, 400, 700}
+
+// accentColors holds the shades of each accent color of Colors, whose
+// primary value is shade 200
+var accentColors = map[string][]string{
+	"redAccent":        {"ff8a80", "ff5252", "ff1744", "d50000"},
+	"pinkAccent":       {"ff80ab", "ff4081", "f50057", "c51162"},
+	"purpleAccent":     {"ea80fc", "e040fb", "d500f9", "aa00ff"},
+	"deepPurpleAccent": {"b388ff", "7c4dff", "651fff", "6200ea"},
+	"indigoAccent":     {"8c9eff", "536dfe", "3d5afe", "304ffe"},
+	"blueAccent":       {"82b1ff", "448aff", "2979ff", "2962ff"},
+	"lightBlueAccent":  {"80d8ff", "40c4ff", "00b0ff", "0091ea"},
+	"cyanAccent":       {"84ffff", "18ffff", "00e5ff", "00b8d4"},
+	"tealAccent":       {"a7ffeb", "64ffda", "1de9b6", "00bfa5"},
+	"greenAccent":      {"b9f6ca", "69f0ae", "00e676", "00c853"},
+	"lightGreenAccent": {"ccff90", "b2ff59", "76ff03", "64dd17"},
+	"limeAccent":       {"f4ff81", "eeff41", "c6ff00", "aeea00"},
+	"yellowAccent":     {"ffff8d", "ffff00", "ffea00", "ffd600"},
+	"amberAccent":      {"ffe57f", "ffd740", "ffc400", "ffab00"},
+	"orangeAccent":     {"ffd180", "ffab40", "ff9100", "ff6d00"},
+	"deepOrangeAccent": {"ff9e80", "ff6e40", "ff3d00", "dd2c00"},
+}
+
+// plainColors holds the colors of Colors that have no shades
+var plainColors = map[string]string{
+	"transparent": "transparent",
+	"black":       "#000000",
+	"black87":     "rgba(0, 0, 0, 0.87)",
+	"black54":     "rgba(0, 0, 0, 0.54)",
+	"black45":     "rgba(0, 0, 0, 0.45)",
+	"black38":     "rgba(0, 0, 0, 0.38)",
+	"black26":     "rgba(0, 0, 0, 0.26)",
+	"black12":     "rgba(0, 0, 0, 0.12)",
+	"white":       "#ffffff",
+	"white70":     "rgba(255, 255, 255, 0.7)",
+	"white60":     "rgba(255, 255, 255, 0.6)",
+	"white54":     "rgba(255, 255, 255, 0.54)",
+	"white38":     "rgba(255, 255, 255, 0.38)",
+	"white30":     "rgba(255, 255, 255, 0.3)",
+	"white24":     "rgba(255, 255, 255, 0.24)",
+	"white12":     "rgba(255, 255, 255, 0.12)",
+	"white10":     "rgba(255, 255, 255, 0.1)",
+}
+
+// colorValue returns the CSS value of a reference to a color of Colors,
+// such as Colors.blue, Colors.grey[300] or Colors.red.shade700
+func colorValue(name string) (string, bool) {
+	name, ok := strings.CutPrefix(name, "Colors.")
+	if !ok {
+		return "", false
+	}
+	if value, ok := plainColors[name]; ok {
+		return value, true
+	}
+
+	shade := 0
+	if base, index, ok := strings.Cut(name, "["); ok && strings.HasSuffix(index, "]") {
+		if _, err := fmt.Sscanf(index, "%d]", &shade); err != nil {
+			return "", false
+		}
+		name = base
+	} else if base, index, ok := strings.Cut(name, ".shade"); ok {
+		if _, err := fmt.Sscanf(index, "%d", &shade); err != nil {
+			return "", false
+		}
+		name = base
+	}
+
+	shades, primary, values := materialShades, 500, materialColors[name]
+	if values == nil {
+		shades, primary, values = accentShades, 200, accentColors[name]
+	}
+	if values == nil {
+		return "", false
+	}
+	if shade == 0 {
+		shade = primary
+	}
+	for i, s := range shades {
+		if s == shade {
+			return "#" + values[i], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"compiler-go/internal/sourcemap"
+	"compiler-go/pkg/ast"
+)
+
+// The helpers in this file are the only way generated code quotes user
+// input. Strings from Dart source are escaped so that they cannot end
+// their literal or the surrounding script, or hold a NUL byte, which
+// delimits source map marks, and names are checked before they are used
+// as identifiers or keys.
+
+var (
+	stringEscaper = strings.NewReplacer(
+		`\`, `\\`, "'", `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`,
+		"\u2028", `\u2028`, "\u2029", `\u2029`, "</", `<\/`, "<!--", `<\!--`, "\x00", `\x00`,
+	)
+	templateEscaper = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", "${", `\${`, "\r", `\r`,
+		"\u2028", `\u2028`, "\u2029", `\u2029`, "</", `<\/`, "<!--", `<\!--`, "\x00", `\x00`,
+	)
+)
+
+// reservedWords lists the words JavaScript reserves in strict mode, which
+// ES modules always use, and the names the generated code relies on
+var reservedWords = map[string]bool{
+	"arguments": true, "await": true, "break": true, "case": true, "catch": true,
+	"class": true, "const": true, "continue": true, "debugger": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"eval": true, "export": true, "extends": true, "false": true,
+	"finally": true, "for": true, "function": true, "if": true,
+	"implements": true, "import": true, "in": true, "instanceof": true,
+	"interface": true, "let": true, "new": true, "null": true,
+	"package": true, "private": true, "protected": true, "public": true,
+	"return": true, "static": true, "super": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "typeof": true,
+	"undefined": true, "var": true, "void": true, "while": true, "with": true,
+	"yield": true,
+}
+
+// mark returns the source map mark of the start of span, or "" unless
+// the code being generated is mapped. Marks precede the code of the
+// widget constructors, callbacks, methods and statements of the source,
+// and are only removed by sourcemap.Extract.
+func (g *JSGenerator) mark(span ast.Span) string {
+	if !g.sourceMaps || !span.IsValid() {
+		return ""
+	}
+	return sourcemap.Mark(span.File, span.Start.Offset)
+}
+
+// jsString returns s as a single-quoted JavaScript string literal
+func jsString(s string) string {
+	return "'" + stringEscaper.Replace(s) + "'"
+}
+
+// jsTemplateText escapes s for use as the text of a template literal
+func jsTemplateText(s string) string {
+	return templateEscaper.Replace(s)
+}
+
+// isIdentifierName reports whether name can be written unquoted as a
+// property key or after a dot
+func isIdentifierName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isIdentifierChar(name[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifierChar reports whether c can appear in an ASCII identifier,
+// at its start if first is set
+func isIdentifierChar(c byte, first bool) bool {
+	letter := c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	return letter || (!first && c >= '0' && c <= '9')
+}
+
+// jsIdent returns a Dart name as a JavaScript binding identifier. Names
+// that are reserved in JavaScript get a trailing $, so a Dart variable
+// called delete becomes delete$, and characters that cannot appear in an
+// identifier are replaced by their code point.
+func jsIdent(name string) string {
+	if reservedWords[name] {
+		return name + "$"
+	}
+	if isIdentifierName(name) {
+		return name
+	}
+	var b strings.Builder
+	for i, r := range name {
+		if r < utf8.RuneSelf && isIdentifierChar(byte(r), i == 0) {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "$%X", r)
+		}
+	}
+	return b.String()
+}
+
+// jsKey returns name as an object literal key, quoted if it is not an
+// identifier name
+func jsKey(name string) string {
+	if isIdentifierName(name) {
+		return name
+	}
+	return jsString(name)
+}
+
+// jsProp returns a key: value entry of an object literal
+func jsProp(key, value string) string {
+	return jsKey(key) + ": " + value
+}
+
+// jsObject returns an object literal with the given entries
+func jsObject(entries []string) string {
+	return "{" + strings.Join(entries, ", ") + "}"
+}
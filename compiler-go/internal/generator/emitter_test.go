@@ -0,0 +1,31 @@
+package generator
+
+import "testing"
+
+func TestJSIdent(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"count", "count"},
+		{"_count", "_count"},
+		{"$price", "$price"},
+		{"delete", "delete$"},
+		{"yield", "yield$"},
+		{"let", "let$"},
+		{"arguments", "arguments$"},
+		{"undefined", "undefined$"},
+		{"of", "of"},
+		{"async", "async"},
+		{"größe", "gr$F6$DFe"},
+	}
+	for _, tt := range tests {
+		if got := jsIdent(tt.name); got != tt.want {
+			t.Errorf("jsIdent(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	for word := range reservedWords {
+		if got := jsIdent(word); got != word+"$" {
+			t.Errorf("jsIdent(%q) = %q, want %q", word, got, word+"$")
+		}
+	}
+}
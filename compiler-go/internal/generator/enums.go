@@ -0,0 +1,58 @@
+package generator
+
+// enumValues maps the Flutter enum values that have a CSS equivalent to
+// the value the runtime applies
+var enumValues = map[string]string{
+	"MainAxisAlignment.start":        "flex-start",
+	"MainAxisAlignment.end":          "flex-end",
+	"MainAxisAlignment.center":       "center",
+	"MainAxisAlignment.spaceBetween": "space-between",
+	"MainAxisAlignment.spaceAround":  "space-around",
+	"MainAxisAlignment.spaceEvenly":  "space-evenly",
+
+	"CrossAxisAlignment.start":    "flex-start",
+	"CrossAxisAlignment.end":      "flex-end",
+	"CrossAxisAlignment.center":   "center",
+	"CrossAxisAlignment.stretch":  "stretch",
+	"CrossAxisAlignment.baseline": "baseline",
+
+	"FontWeight.normal": "400",
+	"FontWeight.bold":   "700",
+	"FontWeight.w100":   "100",
+	"FontWeight.w200":   "200",
+	"FontWeight.w300":   "300",
+	"FontWeight.w400":   "400",
+	"FontWeight.w500":   "500",
+	"FontWeight.w600":   "600",
+	"FontWeight.w700":   "700",
+	"FontWeight.w800":   "800",
+	"FontWeight.w900":   "900",
+
+	"FontStyle.normal": "normal",
+	"FontStyle.italic": "italic",
+
+	"TextDecoration.none":        "none",
+	"TextDecoration.underline":   "underline",
+	"TextDecoration.overline":    "overline",
+	"TextDecoration.lineThrough": "line-through",
+
+	"TextAlign.left":    "left",
+	"TextAlign.right":   "right",
+	"TextAlign.center":  "center",
+	"TextAlign.justify": "justify",
+	"TextAlign.start":   "start",
+	"TextAlign.end":     "end",
+}
+
+// generateReference converts a reference to an enum value or static
+// member. Enum values and colors with a CSS equivalent are mapped to it
+// and other references are kept as their source text.
+func (g *JSGenerator) generateReference(name string) string {
+	if value, ok := enumValues[name]; ok {
+		return jsString(value)
+	}
+	if value, ok := colorValue(name); ok {
+		return jsString(value)
+	}
+	return jsString(name)
+}
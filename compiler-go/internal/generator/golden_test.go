@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"compiler-go/internal/parser"
+	"compiler-go/pkg/ast"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// TestGolden compiles the Dart files in testdata/golden as the modules of
+// one project, the file calling runApp being the entry, and compares each
+// module with the .js file next to its source. Run with -update after an
+// intended change to the generated code.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/golden/*.dart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no golden inputs found")
+	}
+	got := generateProject(t, inputs)
+
+	// Map iteration order changes from run to run, so compiling the same
+	// files repeatedly catches output that depends on it
+	for i := 0; i < 20; i++ {
+		again := generateProject(t, inputs)
+		for _, input := range inputs {
+			if again[input] != got[input] {
+				t.Fatalf("output of %s differs between runs of the same input", input)
+			}
+		}
+	}
+
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			golden := strings.TrimSuffix(input, ".dart") + ".js"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got[input]), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if got[input] != string(want) {
+				t.Errorf("output does not match %s (run go test -update if the change is intended)", golden)
+			}
+		})
+	}
+}
+
+// generateProject parses the Dart files at paths and generates the module
+// of each, by path, with a fresh parser and generator. The modules are
+// generated as if written to an output directory named out.
+func generateProject(t *testing.T, paths []string) map[string]string {
+	t.Helper()
+	units := make([]*ast.CompilationUnit, len(paths))
+	symbols := NewSymbolTable()
+	modules := make(map[string]string, len(paths))
+	for i, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unit, diags := parser.NewParser().ParseUnit(path, string(source))
+		if err := diags.Err(); err != nil {
+			t.Fatal(err)
+		}
+		units[i] = unit
+		symbols.AddUnit(unit)
+		modules[path] = filepath.Join("out", strings.TrimSuffix(filepath.Base(path), ".dart")+".js")
+	}
+
+	code := make(map[string]string, len(paths))
+	g := NewJSGenerator()
+	g.SetSourceDir("testdata/golden")
+	g.SetSymbols(symbols)
+	for i, unit := range units {
+		js, err := g.GenerateModule(unit, ModuleOptions{
+			Path:    modules[paths[i]],
+			Runtime: filepath.Join("out", RuntimeModule),
+			Entry:   unit.Entry != nil,
+			Package: "golden",
+			Modules: modules,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diags := g.Diagnostics(); len(diags) > 0 {
+			t.Fatalf("%s: unexpected diagnostics: %v", paths[i], diags)
+		}
+		code[paths[i]] = js
+	}
+	return code
+}
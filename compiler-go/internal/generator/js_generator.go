@@ -5,13 +5,30 @@ import (
 	"strings"
 	"text/template"
 
-	"compiler-go/internal/ast"
 	"compiler-go/internal/config"
+	"compiler-go/internal/diag"
+	"compiler-go/pkg/ast"
+)
+
+// Diagnostic codes reported by the JavaScript generator
+const (
+	codeUnsupportedWidget     = "G001"
+	codeUnresolvedIdentifier  = "G003"
+	codeDuplicateWidget       = "G004"
+	codeUnsupportedExpression = "G005"
 )
 
 type JSGenerator struct {
-	templates *template.Template
-	sourceDir string
+	templates    *template.Template
+	sourceDir    string
+	config       *config.VortexConfig // loaded from sourceDir if nil
+	diagnostics  diag.List
+	symbols      *SymbolTable
+	classes      map[string]*ast.WidgetClass
+	currentClass *ast.WidgetClass
+	scopes       []map[string]bool // local variables of the code being translated
+	indent       string            // indentation of the statement being translated
+	sourceMaps   bool              // the code being generated carries source map marks
 }
 
 func NewJSGenerator() *JSGenerator {
@@ -24,78 +41,188 @@ func (g *JSGenerator) SetSourceDir(dir string) {
 	g.sourceDir = dir
 }
 
-// Generate converts Flutter widgets to JavaScript code
-func (g *JSGenerator) Generate(widgetTree *ast.WidgetTree) (string, error) {
-	// Load config
-	cfg, err := config.LoadConfig(g.sourceDir)
-	if err != nil {
-		return "", fmt.Errorf("error loading config: %v", err)
+// SetConfig sets the project config, which is otherwise loaded from the
+// source directory by every call to GenerateRuntime or Bundle. The config
+// is only read, so one config can be shared by generators running in
+// parallel.
+func (g *JSGenerator) SetConfig(cfg *config.VortexConfig) {
+	g.config = cfg
+}
+
+// loadConfig returns the config set with SetConfig or else loads it
+func (g *JSGenerator) loadConfig() (*config.VortexConfig, error) {
+	if g.config != nil {
+		return g.config, nil
+	}
+	return config.LoadConfig(g.sourceDir)
+}
+
+// SetSymbols sets the project-wide symbol table used to resolve widgets
+// declared in other files
+func (g *JSGenerator) SetSymbols(symbols *SymbolTable) {
+	g.symbols = symbols
+}
+
+// Diagnostics returns the warnings reported for the last module generated
+func (g *JSGenerator) Diagnostics() diag.List {
+	return g.diagnostics
+}
+
+// warnf records a warning, ignoring repeats for the same span and code
+// since some widgets are generated more than once
+func (g *JSGenerator) warnf(span ast.Span, code, format string, args ...interface{}) {
+	d := diag.Warningf(span, code, format, args...)
+	for _, existing := range g.diagnostics {
+		if existing == d {
+			return
+		}
 	}
+	g.diagnostics = append(g.diagnostics, d)
+}
 
-	// Generate imports
-	imports := g.generateImports(cfg)
+// generateClasses generates a class for every widget defined in unit,
+// exported if export is set, and returns them along with the entry
+// widget: the one passed to runApp, or else the first class with a build
+// method. The entry is nil if unit has no widget classes.
+func (g *JSGenerator) generateClasses(unit *ast.CompilationUnit, export bool) (*ast.WidgetClass, string) {
+	g.classes = make(map[string]*ast.WidgetClass)
+	var entry *ast.WidgetClass
+	for _, class := range unit.Classes {
+		if class.Build == nil {
+			continue
+		}
+		g.classes[class.Name] = class
+		if entry == nil || (unit.Entry != nil && class.Name == unit.Entry.Name) {
+			entry = class
+		}
+	}
+
+	var classDefs strings.Builder
+	for _, class := range unit.Classes {
+		if class.Build != nil {
+			classDefs.WriteString(g.generateClass(class, export))
+		}
+	}
+	return entry, classDefs.String()
+}
+
+// generateApp returns the root App class, which extends the entry widget
+// and takes the props passed to runApp
+func (g *JSGenerator) generateApp(unit *ast.CompilationUnit, entry *ast.WidgetClass) string {
+	entryProps := "{}"
+	if unit.Entry != nil && unit.Entry.Name == entry.Name {
+		entryProps = g.generateCustomWidgetProps(entry, unit.Entry)
+	}
+	return fmt.Sprintf(`class App extends %s {
+  constructor() {
+    super(%s);
+    this.isRootApp = true;
+  }
+}`, jsIdent(entry.Name), entryProps)
+}
+
+// generateClass converts a widget class to a JavaScript class whose
+// constructor fields are read from this.props. The fields of a stateful
+// widget's State class become this.state, and its methods become methods
+// of the class. The class is exported if export is set.
+func (g *JSGenerator) generateClass(class *ast.WidgetClass, export bool) string {
+	g.currentClass = class
+	defer func() { g.currentClass = nil }()
+
+	// Parameter defaults and field initializers become default props
+	var defaults []string
+	params := make(map[string]bool)
+	for _, param := range class.Parameters {
+		params[param.Name] = true
+		if param.Default != nil {
+			defaults = append(defaults, jsProp(param.Name, g.translateExpr(param.Default)))
+		}
+	}
+	for _, field := range class.Fields {
+		if !field.Static && !params[field.Name] && field.Initializer != nil {
+			defaults = append(defaults, jsProp(field.Name, g.translateExpr(field.Initializer)))
+		}
+	}
+	propsInit := "props"
+	if len(defaults) > 0 {
+		propsInit = jsObject(append(defaults, "...props"))
+	}
 
-	// Find all custom widgets
-	customWidgets := make(map[string]bool)
-	g.findCustomWidgets(widgetTree.Root, customWidgets)
+	stateInit := "{}"
+	var methods strings.Builder
+	if state := class.State; state != nil {
+		var fields []string
+		for _, field := range state.Fields {
+			if field.Static {
+				continue
+			}
+			value := "null"
+			if field.Initializer != nil {
+				value = g.translateExpr(field.Initializer)
+			}
+			fields = append(fields, jsProp(field.Name, value))
+		}
+		if len(fields) > 0 {
+			stateInit = jsObject(fields)
+		}
+	}
+	for _, method := range g.methods() {
+		methods.WriteString("\n" + g.translateMethod(method))
+	}
 
-	// Generate custom widget class definitions
-	var customWidgetDefs strings.Builder
-	for widgetName := range customWidgets {
-		customWidgetDefs.WriteString(fmt.Sprintf(`
-class %s extends FlutterUI {
+	keyword := "class"
+	if export {
+		keyword = "export class"
+	}
+	return fmt.Sprintf(`
+%s %s extends FlutterUI {
   constructor(props = {}, children = []) {
     super();
-    this.props = props;
+    this.props = %s;
     this.children = children;
-    this.state = {};
+    this.state = %s;
   }
-
+%s
   buildUI() {
-    return this.Scaffold({
-      appBar: this.AppBar({
-        title: this.Text(this.props.title || '')
-      }),
-      body: this.Center({
-        child: this.Column({
-          mainAxisAlignment: 'center',
-          children: [
-            this.Text('Hello, World!', {
-              style: {
-                fontSize: '24px',
-                fontWeight: 'bold'
-              }
-            }),
-            this.SizedBox({ height: 20 }),
-            this.ElevatedButton({
-              onPressed: () => console.log('Button pressed!')
-            }, [
-              this.Text('Click Me')
-            ])
-          ]
-        })
-      }),
-      floatingActionButton: this.FloatingActionButton({
-        onPressed: () => console.log('FAB pressed!')
-      }, [
-        this.Icon({ icon: 'add' })
-      ])
-    });
-  }
+%s  }
+}
+`, keyword, jsIdent(class.Name), propsInit, stateInit, methods.String(), g.generateBuild(class.Build))
 }
-`, widgetName))
-	}
 
-	// Generate the widget code
-	widgetCode := g.generateWidgetCode(widgetTree.Root)
+// generateBuild converts the body of a build method: the statements
+// before its return, which may declare variables the widget tree uses,
+// and the return of the tree
+func (g *JSGenerator) generateBuild(build *ast.WidgetTree) string {
+	g.pushScope()
+	defer g.popScope()
 
-	// Combine everything
-	code := fmt.Sprintf(`%s
+	g.indent = "    "
+	defer func() { g.indent = "" }()
 
-%s
+	var b strings.Builder
+	for _, stmt := range build.Stmts {
+		b.WriteString(g.translateStmt(stmt))
+	}
+	b.WriteString(g.indent + "return " + g.generateWidgetCode(build.Root) + ";\n")
+	return b.String()
+}
+
+// bootstrap starts the root App once the page has loaded
+const bootstrap = `// Initialize the app
+document.addEventListener('DOMContentLoaded', () => {
+  window.app = new App();
+  window.app.init();
+});
+`
+
+// runtimeClass returns the FlutterUI class, the runtime every generated
+// widget extends
+func runtimeClass(cfg *config.VortexConfig) string {
+	return fmt.Sprintf(`class FlutterUI {
+  // The components being built, innermost last, with the components
+  // each mounted by its previous build
+  static building = [];
 
-// Flutter to Web UI Framework
-class FlutterUI {
   constructor() {
     this.state = {};
     this.elements = new Map();
@@ -113,12 +240,6 @@ class FlutterUI {
         this.render();
       }
     };
-
-    // Handle browser back/forward
-    window.addEventListener('popstate', () => {
-      this.router.currentPath = window.location.pathname;
-      this.render();
-    });
   }
 
   setupStyles() {
@@ -261,7 +382,7 @@ class FlutterUI {
         display: flex;
         flex-direction: column;
         min-height: 100vh;
-        background-color: white;
+        background-color: var(--scaffold-background, white);
       }
 
       .scaffold-body {
@@ -284,6 +405,8 @@ class FlutterUI {
   }
 
   init() {
+    // Pages pushed with Navigator.push, on top of the app's home page
+    this.pages = [];
     this.setupEventListeners();
     this.render();
   }
@@ -291,16 +414,159 @@ class FlutterUI {
   setupEventListeners() {
     document.addEventListener('click', (e) => this.handleClick(e));
     document.addEventListener('input', (e) => this.handleInput(e));
+
+    // Handle browser back/forward
+    window.addEventListener('popstate', (e) => {
+      const depth = (e.state && e.state.page) || 0;
+      this.pages = this.pages.slice(0, depth);
+      this.router.currentPath = window.location.pathname;
+      this.render();
+    });
+  }
+
+  // navigatorPush shows a page on top of the current one, or in place of
+  // it when replace is set
+  navigatorPush(page, replace = false) {
+    const app = window.app;
+    if (replace && app.pages.length > 0) {
+      app.pages[app.pages.length - 1] = page;
+      window.history.replaceState({ page: app.pages.length }, '');
+    } else {
+      app.pages.push(page);
+      window.history.pushState({ page: app.pages.length }, '');
+    }
+    app.render();
   }
 
-  setState(newState) {
-    this.state = { ...this.state, ...newState };
+  // navigatorPop returns to the previous page through the browser history
+  // so that the back button stays in sync
+  navigatorPop() {
+    if (window.app.pages.length > 0) {
+      window.history.back();
+    }
+  }
+
+  // Lifecycle methods overridden by stateful widgets
+  initState() {}
+
+  dispose() {}
+
+  // setState accepts new state to merge, or a function that updates
+  // this.state in place, and re-renders the component
+  setState(update) {
+    if (typeof update === 'function') {
+      update();
+    } else {
+      this.state = { ...this.state, ...update };
+    }
     if (this.isRootApp) {
       this.render();
-    } else if (this.parentElement) {
-      const newElement = this.buildUI();
-      this.parentElement.replaceChild(newElement, this.renderedElement);
-      this.renderedElement = newElement;
+    } else if (this.renderedElement && this.renderedElement.parentNode) {
+      const oldElement = this.renderedElement;
+      this.preserveFocus(oldElement.parentNode, () => {
+        this.renderedElement = this.build();
+        oldElement.replaceWith(this.renderedElement);
+      });
+    }
+  }
+
+  // build builds the component's element, calling initState before the
+  // first build. The components mounted by the previous build are matched
+  // to those of this one, by key or else by position and class, so that
+  // their state survives; the rest are disposed.
+  build() {
+    if (!this.initialized) {
+      this.initialized = true;
+      this.initState();
+    }
+    const frame = { previous: this.mounted || [], mounted: [], position: 0 };
+    FlutterUI.building.push(frame);
+    try {
+      return this.buildUI();
+    } finally {
+      FlutterUI.building.pop();
+      this.mounted = frame.mounted;
+      frame.previous.filter(c => !frame.mounted.includes(c)).forEach(c => c.unmount());
+    }
+  }
+
+  // reconcile returns the component from the previous build of the one
+  // being built that this component takes the place of, given this
+  // component's props and children, or this component if there is none
+  reconcile() {
+    const frame = FlutterUI.building[FlutterUI.building.length - 1];
+    if (!frame) {
+      return this;
+    }
+    const key = this.props ? this.props.key : undefined;
+    let match;
+    if (key !== undefined && key !== null) {
+      match = frame.previous.find(c => c.constructor === this.constructor && c.props.key === key);
+    } else {
+      match = frame.previous[frame.position];
+      if (match && (match.constructor !== this.constructor || (match.props.key !== undefined && match.props.key !== null))) {
+        match = undefined;
+      }
+    }
+    frame.position++;
+    if (!match || frame.mounted.includes(match)) {
+      frame.mounted.push(this);
+      return this;
+    }
+    match.props = this.props;
+    match.children = this.children;
+    frame.mounted.push(match);
+    return match;
+  }
+
+  // unmount disposes the component and the components it mounted
+  unmount() {
+    (this.mounted || []).forEach(c => c.unmount());
+    this.mounted = [];
+    this.renderedElement = null;
+    this.dispose();
+  }
+
+  // mount builds a component nested in another one, remembering its
+  // element so that setState can replace it
+  mount() {
+    const component = this.reconcile();
+    component.renderedElement = component.build();
+    return component.renderedElement;
+  }
+
+  // preserveFocus runs update, which replaces the content of root, and
+  // then moves the focus, with the value and selection of a text field,
+  // to the element at the same position as the one that had it
+  preserveFocus(root, update) {
+    const active = document.activeElement;
+    if (!active || active === document.body || !root.contains(active)) {
+      update();
+      return;
+    }
+    const path = [];
+    for (let node = active; node !== root; node = node.parentNode) {
+      path.unshift(Array.prototype.indexOf.call(node.parentNode.childNodes, node));
+    }
+    const { value, selectionStart, selectionEnd } = active;
+    update();
+    let node = root;
+    for (const index of path) {
+      node = node && node.childNodes[index];
+    }
+    if (!node || node.tagName !== active.tagName) {
+      return;
+    }
+    if (value !== undefined && node.value !== value) {
+      node.value = value;
+    }
+    node.focus();
+    if (typeof selectionStart === 'number' && typeof node.setSelectionRange === 'function') {
+      try {
+        node.setSelectionRange(selectionStart, selectionEnd);
+      } catch (e) {
+        // Some input types have no selection
+      }
     }
   }
 
@@ -329,11 +595,21 @@ class FlutterUI {
       console.error('.app root element not found');
       return;
     }
-    appRootElement.innerHTML = '';
-    const content = this.buildUI();
-    if (content) {
-      appRootElement.appendChild(content);
-    }
+    const page = this.pages && this.pages[this.pages.length - 1];
+    this.preserveFocus(appRootElement, () => {
+      appRootElement.innerHTML = '';
+      let content;
+      if (!page) {
+        content = this.build();
+      } else if (typeof page.buildUI === 'function') {
+        content = page.mount();
+      } else {
+        content = page;
+      }
+      if (content) {
+        appRootElement.appendChild(content);
+      }
+    });
   }
 
   createElement(tag, props = {}, children = []) {
@@ -363,6 +639,8 @@ class FlutterUI {
     childNodes.forEach(child => {
       if (child instanceof Node) {
         element.appendChild(child);
+      } else if (child && typeof child.buildUI === 'function') {
+        element.appendChild(child.mount());
       } else if (typeof child === 'string' || typeof child === 'number') {
         element.appendChild(document.createTextNode(child.toString()));
       }
@@ -377,14 +655,17 @@ class FlutterUI {
 
   Text(text, props = {}) {
     const actualText = (props && props.text !== undefined) ? props.text : text;
+    const textAlign = props.textAlign;
     delete props.text;
+    delete props.textAlign;
     return this.createElement('span', {
       className: 'text-base ' + (props.className || ''),
+      ...props,
       style: {
         color: 'var(--text-primary)',
+        ...(textAlign ? { display: 'block', textAlign: textAlign } : {}),
         ...props.style
-      },
-      ...props
+      }
     }, actualText !== undefined ? String(actualText) : '');
   }
 
@@ -424,36 +705,52 @@ class FlutterUI {
   }
 
   Container(props = {}, children = []) {
+    const color = props.color;
+    delete props.color;
     return this.createElement('div', {
       className: 'container ' + (props.className || ''),
-      ...props
+      ...props,
+      style: {
+        ...(color ? { backgroundColor: color } : {}),
+        ...props.style
+      }
     }, children);
   }
 
+  // flexStyle moves a Row or Column's alignment props to its style
+  flexStyle(props) {
+    const style = { ...props.style };
+    if (props.mainAxisAlignment) {
+      style.justifyContent = props.mainAxisAlignment;
+    }
+    if (props.crossAxisAlignment) {
+      style.alignItems = props.crossAxisAlignment;
+    }
+    delete props.mainAxisAlignment;
+    delete props.crossAxisAlignment;
+    return style;
+  }
+
   Row(props = {}, children = []) {
+    const style = this.flexStyle(props);
     return this.createElement('div', {
       className: 'flex row ' + (props.className || ''),
-      ...props
+      ...props,
+      style: style
     }, children);
   }
 
   Column(props = {}, children = []) {
-    const mainAxisAlignment = props.mainAxisAlignment;
-    delete props.mainAxisAlignment;
-    
-    let className = 'flex column ';
-    if (mainAxisAlignment) {
-      className += 'justify-' + mainAxisAlignment + ' ';
-    }
-    className += (props.className || '');
+    const style = this.flexStyle(props);
     
     // Use children from props if available, otherwise use the children parameter
     const actualChildren = props.children || children;
     delete props.children;
     
     return this.createElement('div', {
-      className: className,
-      ...props
+      className: 'flex column ' + (props.className || ''),
+      ...props,
+      style: style
     }, actualChildren);
   }
 
@@ -461,20 +758,23 @@ class FlutterUI {
     const homeWidget = props.home;
     delete props.home;
 
-    if (props.themeData) {
-      const theme = props.themeData;
-      if (theme.primarySwatch) {
-        document.documentElement.style.setProperty('--primary-color', theme.primarySwatch);
+    if (props.theme) {
+      const theme = props.theme;
+      const scheme = theme.colorScheme || {};
+      const primary = theme.primaryColor || theme.primarySwatch || scheme.primary || scheme.seedColor;
+      if (primary) {
+        document.documentElement.style.setProperty('--primary-color', primary);
+      }
+      if (theme.scaffoldBackgroundColor) {
+        document.documentElement.style.setProperty('--scaffold-background', theme.scaffoldBackgroundColor);
       }
-      delete props.themeData;
+      delete props.theme;
     }
 
     let homeElement = null;
     if (homeWidget) {
       if (homeWidget && typeof homeWidget.buildUI === 'function') {
-        homeElement = homeWidget.buildUI();
-        homeWidget.parentElement = this.createElement('div');
-        homeWidget.renderedElement = homeElement;
+        homeElement = homeWidget.mount();
       } else if (homeWidget instanceof Node) {
         homeElement = homeWidget;
       }
@@ -498,9 +798,7 @@ class FlutterUI {
     let appBarElement = null;
     if (appBarWidget) {
       if (appBarWidget && typeof appBarWidget.buildUI === 'function') {
-        appBarElement = appBarWidget.buildUI();
-        appBarWidget.parentElement = this.createElement('header');
-        appBarWidget.renderedElement = appBarElement;
+        appBarElement = appBarWidget.mount();
       } else if (appBarWidget instanceof Node) {
         appBarElement = appBarWidget;
       }
@@ -509,24 +807,21 @@ class FlutterUI {
     let bodyContentNodes = [];
     if (bodyWidget) {
       if (bodyWidget && typeof bodyWidget.buildUI === 'function') {
-        const bodyElement = bodyWidget.buildUI();
-        bodyContentNodes = [bodyElement];
-        bodyWidget.parentElement = this.createElement('main');
-        bodyWidget.renderedElement = bodyElement;
+        bodyContentNodes = [bodyWidget.mount()];
       } else if (Array.isArray(bodyWidget)) {
-        bodyContentNodes = bodyWidget.map(item => item && typeof item.buildUI === 'function' ? item.buildUI() : (item instanceof Node ? item : null)).filter(n => n);
+        bodyContentNodes = bodyWidget.map(item => item && typeof item.buildUI === 'function' ? item.mount() : (item instanceof Node ? item : null)).filter(n => n);
       } else if (bodyWidget instanceof Node) {
         bodyContentNodes = [bodyWidget];
       }
     } else if (children) {
       bodyContentNodes = Array.isArray(children) ? children : [children];
-      bodyContentNodes = bodyContentNodes.map(item => item && typeof item.buildUI === 'function' ? item.buildUI() : (item instanceof Node ? item : null)).filter(n => n);
+      bodyContentNodes = bodyContentNodes.map(item => item && typeof item.buildUI === 'function' ? item.mount() : (item instanceof Node ? item : null)).filter(n => n);
     }
 
     let fabElement = null;
     if (floatingActionButtonWidget) {
       if (floatingActionButtonWidget && typeof floatingActionButtonWidget.buildUI === 'function') {
-        fabElement = floatingActionButtonWidget.buildUI();
+        fabElement = floatingActionButtonWidget.mount();
       } else if (floatingActionButtonWidget instanceof Node) {
         fabElement = floatingActionButtonWidget;
       }
@@ -567,7 +862,7 @@ class FlutterUI {
     let titleElement = null;
     if (titleWidget) {
       if (titleWidget && typeof titleWidget.buildUI === 'function') {
-        titleElement = titleWidget.buildUI();
+        titleElement = titleWidget.mount();
       } else if (titleWidget instanceof Node) {
         titleElement = titleWidget;
       } else if (typeof titleWidget === 'string' || typeof titleWidget === 'number') {
@@ -576,12 +871,21 @@ class FlutterUI {
     }
     
     const actionElements = (Array.isArray(children) ? children : (children ? [children] : [])).map(
-      item => item && typeof item.buildUI === 'function' ? item.buildUI() : (item instanceof Node ? item : null)
+      item => item && typeof item.buildUI === 'function' ? item.mount() : (item instanceof Node ? item : null)
     ).filter(n => n);
 
+    const backgroundColor = props.backgroundColor;
+    const foregroundColor = props.foregroundColor;
+    delete props.backgroundColor;
+    delete props.foregroundColor;
     const headerElement = this.createElement('header', {
       className: 'app-bar ' + (props.className || ''),
-      ...props
+      ...props,
+      style: {
+        ...(backgroundColor ? { backgroundColor: backgroundColor } : {}),
+        ...(foregroundColor ? { color: foregroundColor } : {}),
+        ...props.style
+      }
     });
 
     if (titleElement) {
@@ -615,16 +919,54 @@ class FlutterUI {
 
   Icon(props = {}) {
     const iconName = props.icon || 'add';
+    const color = props.color;
+    const size = props.size;
     delete props.icon;
-    
+    delete props.color;
+    delete props.size;
+
     return this.createElement('span', {
       className: 'material-icons ' + (props.className || ''),
+      ...props,
       style: {
-        color: 'white',
+        color: color || 'white',
+        ...(size ? { fontSize: size + 'px' } : {}),
+        ...props.style
+      }
+    }, iconName);
+  }
+
+  GestureDetector(props = {}, children = []) {
+    const onTap = props.onTap;
+    delete props.onTap;
+
+    return this.createElement('div', {
+      className: 'gesture-detector ' + (props.className || ''),
+      onClick: onTap,
+      style: {
+        cursor: onTap ? 'pointer' : 'auto',
         ...props.style
       },
       ...props
-    }, iconName);
+    }, children);
+  }
+
+  InkWell(props = {}, children = []) {
+    return this.GestureDetector(props, children);
+  }
+
+  TextField(props = {}) {
+    const onChanged = props.onChanged;
+    const obscureText = props.obscureText;
+    delete props.onChanged;
+    delete props.obscureText;
+
+    return this.createElement('input', {
+      className: 'text-field ' + (props.className || ''),
+      type: obscureText ? 'password' : 'text',
+      onInput: onChanged ? (e) => onChanged(e.target.value) : undefined,
+      ...props
+    });
   }
 
   // Add navigation methods
@@ -645,65 +987,57 @@ class FlutterUI {
       }
     }, children);
   }
+}`, cfg.Compiler.UseFlutterWind)
 }
 
-%s
-
-// Generated from Flutter
-class App extends FlutterUI {
-  constructor() {
-    super();
-    this.isRootApp = true;
-  }
-  buildUI() {
-    return %s;
-  }
-}
-
-// Initialize the app
-document.addEventListener('DOMContentLoaded', () => {
-  window.app = new App();
-  window.app.init();
-});
-`, imports, customWidgetDefs.String(), cfg.Compiler.UseFlutterWind, widgetCode)
-
-	return code, nil
-}
-
-func (g *JSGenerator) generateImports(cfg *config.VortexConfig) string {
-	imports := []string{
-		"import { createElement } from 'vortex';",
+// lookupClass resolves a user-defined widget, preferring classes declared
+// in the unit being generated over the project-wide symbol table
+func (g *JSGenerator) lookupClass(name string) (*ast.WidgetClass, bool) {
+	if class, ok := g.classes[name]; ok {
+		return class, true
 	}
-
-	if cfg.Compiler.UseFlutterWind {
-		imports = append(imports, "import { tw } from 'flutterwind';")
+	if g.symbols != nil {
+		return g.symbols.Lookup(name)
 	}
-
-	return strings.Join(imports, "\n")
+	return nil, false
 }
 
-// findCustomWidgets finds all custom widget classes in the widget tree
-func (g *JSGenerator) findCustomWidgets(node *ast.WidgetNode, customWidgets map[string]bool) {
-	if node == nil {
-		return
+// isField reports whether name is an instance field or constructor
+// parameter of the class being generated
+func (g *JSGenerator) isField(name string) bool {
+	if g.currentClass == nil {
+		return false
 	}
-
-	// Check if this is a custom widget
-	if strings.HasPrefix(node.Name, "My") || strings.HasPrefix(node.Name, "Custom") {
-		customWidgets[node.Name] = true
+	for _, field := range g.currentClass.Fields {
+		if field.Name == name && !field.Static {
+			return true
+		}
 	}
-
-	// Check children
-	for _, child := range node.Children {
-		g.findCustomWidgets(child, customWidgets)
+	for _, param := range g.currentClass.Parameters {
+		if param.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	// Check widget properties
-	for _, value := range node.Properties {
-		if value.Widget != nil {
-			g.findCustomWidgets(value.Widget, customWidgets)
+// generateIdentifier resolves a variable reference. Fields of the widget
+// being generated, whether written as title, this.title or widget.title,
+// are read from this.props, and fields of its State from this.state.
+func (g *JSGenerator) generateIdentifier(value *ast.IdentRef) string {
+	name := value.Name
+	parts := strings.Split(name, ".")
+	if parts[0] == "this" && len(parts) > 1 {
+		if js, ok := g.resolveMember(parts[1], false); ok {
+			return strings.Join(append([]string{js}, parts[2:]...), ".")
 		}
+	} else if parts[0] == "widget" && len(parts) > 1 && g.isField(parts[1]) {
+		return "this.props." + strings.Join(parts[1:], ".")
+	} else if js, ok := g.resolveName(parts[0], false); ok {
+		return strings.Join(append([]string{js}, parts[1:]...), ".")
 	}
+	g.warnf(value.Span, codeUnresolvedIdentifier, "identifier %s cannot be resolved and was left out", name)
+	return "undefined"
 }
 
 // generateWidgetCode converts a widget node to JavaScript code
@@ -711,19 +1045,32 @@ func (g *JSGenerator) generateWidgetCode(node *ast.WidgetNode) string {
 	if node == nil {
 		return "null"
 	}
+	if js, ok := g.generateStyleValue(node); ok {
+		return js
+	}
 
-	// Convert widget name to camelCase for JavaScript
-	jsName := g.toCamelCase(node.Name)
+	// A widget the runtime does not implement is left out, since a call
+	// of a runtime method that does not exist would throw
+	if _, ok := g.lookupClass(node.Name); !ok && !IsBuiltin(node.Name) {
+		g.warnf(node.Span, codeUnsupportedWidget, "%s is not supported by the runtime and was left out", strings.ReplaceAll(node.Name, "_", "."))
+		return g.mark(node.Span) + "null"
+	}
 
 	// Generate props
-	props := g.generateProps(node.Properties)
+	props := g.generateProps(node)
 
 	// Generate children
 	children := g.generateChildren(node.Children)
 
+	return g.mark(node.Span) + g.generateWidget(node, props, children)
+}
+
+// generateWidget converts a widget node to JavaScript code given its
+// props and children
+func (g *JSGenerator) generateWidget(node *ast.WidgetNode, props, children string) string {
 	// For custom widget classes, create a new instance
-	if strings.HasPrefix(node.Name, "My") || strings.HasPrefix(node.Name, "Custom") {
-		return fmt.Sprintf("new %s(%s, %s)", jsName, props, children)
+	if class, ok := g.lookupClass(node.Name); ok {
+		return fmt.Sprintf("new %s(%s, %s)", jsIdent(node.Name), g.generateCustomWidgetProps(class, node), children)
 	}
 
 	// Special handling for certain widgets
@@ -731,12 +1078,13 @@ func (g *JSGenerator) generateWidgetCode(node *ast.WidgetNode) string {
 	case "MaterialApp":
 		return fmt.Sprintf("this.MaterialApp(%s, %s)", props, children)
 	case "Scaffold":
-		// Special handling for Scaffold to properly handle floatingActionButton
-		floatingActionButton := ""
-		if value, ok := node.Properties["floatingActionButton"]; ok && value.Widget != nil {
-			floatingActionButton = g.generateWidgetCode(value.Widget)
+		// Special handling for Scaffold to properly handle floatingActionButton,
+		// which may also be a variable or conditional holding the button
+		floatingActionButton := "null"
+		if value, ok := node.Properties["floatingActionButton"]; ok {
+			floatingActionButton = g.generateValue(value)
 		}
-		propsWithoutFAB := g.generatePropsWithoutFAB(node.Properties)
+		propsWithoutFAB := g.generatePropsExcept(node, "floatingActionButton")
 		return fmt.Sprintf("this.Scaffold({...%s, floatingActionButton: %s}, %s)",
 			propsWithoutFAB, floatingActionButton, children)
 	case "AppBar":
@@ -744,92 +1092,155 @@ func (g *JSGenerator) generateWidgetCode(node *ast.WidgetNode) string {
 	case "Center":
 		return fmt.Sprintf("this.Center(%s, %s)", props, children)
 	case "Column":
-		// Special handling for Column to properly handle mainAxisAlignment and children
-		mainAxisAlignment := ""
-		if value, ok := node.Properties["mainAxisAlignment"]; ok && value.String != nil {
-			mainAxisAlignment = *value.String
-		}
-		propsWithoutMainAxis := g.generatePropsWithoutMainAxis(node.Properties)
-		return fmt.Sprintf("this.Column({...%s, mainAxisAlignment: '%s', children: %s})",
-			propsWithoutMainAxis, mainAxisAlignment, children)
+		return fmt.Sprintf("this.Column({...%s, children: %s})", props, children)
 	case "Text":
-		return fmt.Sprintf("this.Text(%s, %s)", props, children)
-	case "ElevatedButton":
-		return fmt.Sprintf("this.ElevatedButton(%s, %s)", props, children)
+		// The text is the first positional argument
+		text := "''"
+		if len(node.Arguments) > 0 {
+			text = g.generateValue(node.Arguments[0])
+		}
+		return fmt.Sprintf("this.Text(%s, %s)", text, props)
+	case "ElevatedButton", "FloatingActionButton", "Container", "GestureDetector", "InkWell":
+		// The runtime renders these widgets' child as their children
+		if child, ok := node.Properties["child"].(*ast.WidgetNode); ok {
+			children = fmt.Sprintf("[%s]", g.generateWidgetCode(child))
+			props = g.generatePropsExcept(node, "child")
+		}
+		return fmt.Sprintf("this.%s(%s, %s)", node.Name, props, children)
 	case "SizedBox":
 		return fmt.Sprintf("this.SizedBox(%s)", props)
-	case "FloatingActionButton":
-		return fmt.Sprintf("this.FloatingActionButton(%s, %s)", props, children)
 	case "Icon":
-		iconName := ""
-		if value, ok := node.Properties["icon"]; ok && value.String != nil {
-			iconName = *value.String
+		// The icon is the first positional argument, e.g. Icon(Icons.add)
+		iconName := "''"
+		value, ok := node.Properties["icon"]
+		if len(node.Arguments) > 0 {
+			value, ok = node.Arguments[0], true
 		}
-		propsWithoutIcon := g.generatePropsWithoutIcon(node.Properties)
-		return fmt.Sprintf("this.Icon({...%s, icon: '%s'})", propsWithoutIcon, iconName)
+		if ok {
+			if ref, isRef := value.(*ast.EnumRef); isRef && strings.HasPrefix(ref.Name, "Icons.") {
+				iconName = jsString(strings.TrimPrefix(ref.Name, "Icons."))
+			} else {
+				iconName = g.generateValue(value)
+			}
+		}
+		propsWithoutIcon := g.generatePropsExcept(node, "icon")
+		return fmt.Sprintf("this.Icon({...%s, icon: %s})", propsWithoutIcon, iconName)
 	default:
-		return fmt.Sprintf("this.%s(%s, %s)", jsName, props, children)
+		// Runtime methods are named after the widget
+		return fmt.Sprintf("this.%s(%s, %s)", node.Name, props, children)
+	}
+}
+
+// generateCustomWidgetProps converts the arguments of a custom widget
+// constructor call to props, naming positional arguments after the
+// class's positional constructor parameters
+func (g *JSGenerator) generateCustomWidgetProps(class *ast.WidgetClass, node *ast.WidgetNode) string {
+	var propStrings []string
+	positional := 0
+	for _, param := range class.Parameters {
+		if param.Named {
+			continue
+		}
+		if positional < len(node.Arguments) {
+			propStrings = append(propStrings, jsProp(param.Name, g.generateValue(node.Arguments[positional])))
+		}
+		positional++
+	}
+	named := g.generateProps(node)
+	if len(propStrings) == 0 {
+		return named
 	}
+	if named != "{}" {
+		propStrings = append(propStrings, "..."+named)
+	}
+	return jsObject(propStrings)
 }
 
 // generateProps converts widget properties to JavaScript object
-func (g *JSGenerator) generateProps(props map[string]ast.PropertyValue) string {
-	if len(props) == 0 {
+func (g *JSGenerator) generateProps(node *ast.WidgetNode) string {
+	return g.generatePropsExcept(node)
+}
+
+// generatePropsExcept converts widget properties to a JavaScript object in
+// source order, leaving out children and the named properties
+func (g *JSGenerator) generatePropsExcept(node *ast.WidgetNode, except ...string) string {
+	if len(node.Properties) == 0 {
 		return "{}"
 	}
 
 	var propStrings []string
-	for name, value := range props {
-		if name == "children" {
+	for _, name := range node.PropertyNames() {
+		if name == "children" || contains(except, name) {
 			continue // skip children property, handled as children array
 		}
-		jsName := g.toCamelCase(name)
-		jsValue := g.generatePropertyValue(value)
-		propStrings = append(propStrings, fmt.Sprintf("%s: %s", jsName, jsValue))
+		jsValue := g.generateValue(node.Properties[name])
+		propStrings = append(propStrings, jsProp(name, jsValue))
 	}
 
-	return fmt.Sprintf("{%s}", strings.Join(propStrings, ", "))
+	return jsObject(propStrings)
 }
 
-// generatePropertyValue converts a property value to JavaScript
-func (g *JSGenerator) generatePropertyValue(value ast.PropertyValue) string {
-	switch {
-	case value.String != nil:
-		return fmt.Sprintf("'%s'", *value.String)
-	case value.Number != nil:
-		return fmt.Sprintf("%v", *value.Number)
-	case value.Boolean != nil:
-		return fmt.Sprintf("%v", *value.Boolean)
-	case value.Widget != nil:
-		if value.Widget.Name == "ThemeData" {
-			props := g.generateProps(value.Widget.Properties)
-			return props
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
 		}
-		return g.generateWidgetCode(value.Widget)
-	case value.List != nil:
-		return g.generateList(value.List)
-	case value.Style != nil:
-		var styleStrings []string
-		for k, v := range value.Style {
-			// Convert camelCase to kebab-case for CSS properties
-			jsName := g.toCamelCase(k)
-			styleStrings = append(styleStrings, fmt.Sprintf("%s: '%s'", jsName, v))
+	}
+	return false
+}
+
+// generateValue converts a widget argument to JavaScript
+func (g *JSGenerator) generateValue(value ast.Value) string {
+	switch v := value.(type) {
+	case *ast.StringValue:
+		return jsString(v.Value)
+	case *ast.NumberValue:
+		return fmt.Sprintf("%v", v.Value)
+	case *ast.BoolValue:
+		return fmt.Sprintf("%v", v.Value)
+	case *ast.NullValue:
+		return "null"
+	case *ast.EnumRef:
+		return g.generateReference(v.Name)
+	case *ast.IdentRef:
+		return g.generateIdentifier(v)
+	case *ast.WidgetNode:
+		return g.generateWidgetCode(v)
+	case *ast.ListValue:
+		return g.generateList(v.Elements)
+	case *ast.MapValue:
+		var entries []string
+		for _, entry := range v.Entries {
+			key := "[" + g.generateValue(entry.Key) + "]"
+			if str, ok := entry.Key.(*ast.StringValue); ok {
+				key = jsKey(str.Value)
+			}
+			entries = append(entries, key+": "+g.generateValue(entry.Value))
 		}
-		return fmt.Sprintf("{style: {%s}}", strings.Join(styleStrings, ", "))
+		return jsObject(entries)
+	case *ast.FuncLit:
+		return g.translateExpr(v)
+	case *ast.StringInterpolation:
+		return g.translateExpr(v)
+	case *ast.ExprValue:
+		return g.translateExpr(v.X)
+	case *ast.RawValue:
+		g.unsupported(v, fmt.Sprintf("expression %s", v.Text))
+		return "undefined"
 	default:
 		return "null"
 	}
 }
 
 // generateList converts a list of values to JavaScript array
-func (g *JSGenerator) generateList(items []ast.PropertyValue) string {
+func (g *JSGenerator) generateList(items []ast.Value) string {
 	if len(items) == 0 {
 		return "[]"
 	}
 
 	var itemStrings []string
 	for _, item := range items {
-		itemStrings = append(itemStrings, g.generatePropertyValue(item))
+		itemStrings = append(itemStrings, g.generateValue(item))
 	}
 
 	return fmt.Sprintf("[%s]", strings.Join(itemStrings, ", "))
@@ -848,110 +1259,3 @@ func (g *JSGenerator) generateChildren(children []*ast.WidgetNode) string {
 
 	return fmt.Sprintf("[%s]", strings.Join(childStrings, ", "))
 }
-
-// toCamelCase converts a string to camelCase
-func (g *JSGenerator) toCamelCase(s string) string {
-	// Handle special cases
-	switch s {
-	case "MaterialApp":
-		return "MaterialApp"
-	case "Scaffold":
-		return "Scaffold"
-	case "AppBar":
-		return "AppBar"
-	case "Text":
-		return "Text"
-	case "Container":
-		return "Container"
-	case "Row":
-		return "Row"
-	case "Column":
-		return "Column"
-	case "Button":
-		return "Button"
-	case "TextField":
-		return "TextField"
-	case "Card":
-		return "Card"
-	case "ThemeData":
-		return "themeData"
-	case "mainAxisAlignment":
-		return "mainAxisAlignment"
-	case "fontSize":
-		return "fontSize"
-	case "fontWeight":
-		return "fontWeight"
-	default:
-		// For custom widget classes, keep the original name
-		if strings.HasPrefix(s, "My") || strings.HasPrefix(s, "Custom") {
-			return s
-		}
-		// Convert to camelCase for other cases
-		parts := strings.Split(s, "_")
-		for i, part := range parts {
-			if i == 0 {
-				parts[i] = strings.ToLower(part)
-			} else {
-				parts[i] = strings.Title(part)
-			}
-		}
-		return strings.Join(parts, "")
-	}
-}
-
-// generatePropsWithoutMainAxis generates props without the mainAxisAlignment property
-func (g *JSGenerator) generatePropsWithoutMainAxis(props map[string]ast.PropertyValue) string {
-	if len(props) == 0 {
-		return "{}"
-	}
-
-	var propStrings []string
-	for name, value := range props {
-		if name == "children" || name == "mainAxisAlignment" {
-			continue
-		}
-		jsName := g.toCamelCase(name)
-		jsValue := g.generatePropertyValue(value)
-		propStrings = append(propStrings, fmt.Sprintf("%s: %s", jsName, jsValue))
-	}
-
-	return fmt.Sprintf("{%s}", strings.Join(propStrings, ", "))
-}
-
-// generatePropsWithoutFAB generates props without the floatingActionButton property
-func (g *JSGenerator) generatePropsWithoutFAB(props map[string]ast.PropertyValue) string {
-	if len(props) == 0 {
-		return "{}"
-	}
-
-	var propStrings []string
-	for name, value := range props {
-		if name == "floatingActionButton" {
-			continue
-		}
-		jsName := g.toCamelCase(name)
-		jsValue := g.generatePropertyValue(value)
-		propStrings = append(propStrings, fmt.Sprintf("%s: %s", jsName, jsValue))
-	}
-
-	return fmt.Sprintf("{%s}", strings.Join(propStrings, ", "))
-}
-
-// generatePropsWithoutIcon generates props without the icon property
-func (g *JSGenerator) generatePropsWithoutIcon(props map[string]ast.PropertyValue) string {
-	if len(props) == 0 {
-		return "{}"
-	}
-
-	var propStrings []string
-	for name, value := range props {
-		if name == "icon" {
-			continue
-		}
-		jsName := g.toCamelCase(name)
-		jsValue := g.generatePropertyValue(value)
-		propStrings = append(propStrings, fmt.Sprintf("%s: %s", jsName, jsValue))
-	}
-
-	return fmt.Sprintf("{%s}", strings.Join(propStrings, ", "))
-}
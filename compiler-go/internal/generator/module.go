@@ -0,0 +1,225 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"compiler-go/pkg/ast"
+)
+
+// Diagnostic codes reported for module imports
+const (
+	codeUnresolvedImport = "G006"
+	codeMissingImport    = "G007"
+)
+
+// RuntimeModule is the file name of the runtime module that the modules
+// generated by GenerateModule import
+const RuntimeModule = "vortex-runtime.js"
+
+// ModuleOptions controls how GenerateModule generates a unit
+type ModuleOptions struct {
+	// Path is the output path of the generated module, which the paths
+	// it imports are made relative to
+	Path string
+
+	// Runtime is the output path of the runtime module
+	Runtime string
+
+	// Entry is set for the module loaded by the page, which bootstraps
+	// its entry widget as the root App
+	Entry bool
+
+	// Package is the name of the app's package. Imports of
+	// package:<Package>/ resolve to files in the lib directory of the
+	// source directory.
+	Package string
+
+	// Modules maps the path of each Dart file of the project to the
+	// output path of its module
+	Modules map[string]string
+
+	// SourceMap is set to mark the code with the Dart positions it was
+	// generated from, for sourcemap.Extract to remove
+	SourceMap bool
+}
+
+// GenerateRuntime returns the runtime module, which exports the FlutterUI
+// class that every generated widget extends. If widgets is not nil, the
+// class keeps only the widget methods for widgets, and those the kept
+// methods use, as in a bundle.
+func (g *JSGenerator) GenerateRuntime(widgets map[string]bool) (string, error) {
+	cfg, err := g.loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("error loading config: %v", err)
+	}
+	class := runtimeClass(cfg)
+	if widgets != nil {
+		class = pruneRuntime(class, widgets)
+	}
+	return fmt.Sprintf("// Flutter to Web UI Framework\nexport %s\n", class), nil
+}
+
+// GenerateModule converts every widget class in a compilation unit to an
+// exported JavaScript class of an ES module. The module imports FlutterUI
+// from the runtime module and each widget declared in another file of
+// the project from that file's module. Only the entry module bootstraps
+// a root App.
+func (g *JSGenerator) GenerateModule(unit *ast.CompilationUnit, opts ModuleOptions) (string, error) {
+	m, err := g.generateModule(unit, opts, true)
+	if err != nil {
+		return "", err
+	}
+
+	var code strings.Builder
+	fmt.Fprintf(&code, "import { FlutterUI } from %s;\n", jsString(importPath(opts.Path, opts.Runtime)))
+	for _, imp := range m.Imports {
+		fmt.Fprintf(&code, "import { %s } from %s;\n", strings.Join(imp.Names, ", "), jsString(importPath(opts.Path, opts.Modules[imp.File])))
+	}
+	code.WriteString(m.Code)
+	return code.String(), nil
+}
+
+// Module is a compilation unit generated for a bundle, which holds the
+// code of every module in a scope of its own
+type Module struct {
+	File    string   // the Dart file
+	Imports []Import // sorted by file
+	Classes []string // the classes the module declares, which it exports
+	Code    string   // the class definitions and, for the entry, the bootstrap
+}
+
+// Import lists the widgets a module uses from the module of another file
+type Import struct {
+	File  string
+	Names []string // sorted
+}
+
+// BundleModule converts every widget class in a compilation unit to a
+// JavaScript class, like GenerateModule, for a bundle written by Bundle.
+// The classes are declared in the module's scope rather than exported.
+func (g *JSGenerator) BundleModule(unit *ast.CompilationUnit, opts ModuleOptions) (*Module, error) {
+	return g.generateModule(unit, opts, false)
+}
+
+// generateModule generates the classes of unit, exported if export is
+// set, and finds the widgets it imports
+func (g *JSGenerator) generateModule(unit *ast.CompilationUnit, opts ModuleOptions, export bool) (*Module, error) {
+	g.diagnostics = nil
+	g.sourceMaps = opts.SourceMap
+	defer func() { g.sourceMaps = false }()
+
+	entry, classDefs := g.generateClasses(unit, export)
+	if opts.Entry && entry == nil {
+		return nil, fmt.Errorf("no widget class with a build method found")
+	}
+
+	m := &Module{File: unit.File, Imports: g.moduleImports(unit, opts)}
+	for _, class := range unit.Classes {
+		if class.Build != nil {
+			m.Classes = append(m.Classes, jsIdent(class.Name))
+		}
+	}
+	var code strings.Builder
+	if classDefs != "" {
+		fmt.Fprintf(&code, "\n// Generated from Flutter%s", classDefs)
+	}
+	if opts.Entry {
+		fmt.Fprintf(&code, "\n%s\n\n%s", g.generateApp(unit, entry), bootstrap)
+	}
+	m.Code = code.String()
+	return m, nil
+}
+
+// moduleImports returns the widgets that unit uses from other files of
+// the project. It reports Dart imports that match no file of the project,
+// and widgets used from files that unit does not import, which are
+// imported anyway.
+func (g *JSGenerator) moduleImports(unit *ast.CompilationUnit, opts ModuleOptions) []Import {
+	imported := make(map[string]bool)
+	for _, imp := range unit.Imports {
+		file, ok := g.resolveImport(unit.File, imp.URI, opts.Package)
+		if !ok {
+			continue
+		}
+		if _, ok := opts.Modules[file]; !ok {
+			g.warnf(imp.Span, codeUnresolvedImport, "import %q does not match a file of the project", imp.URI)
+			continue
+		}
+		imported[file] = true
+	}
+
+	names := make(map[string]map[string]bool) // by file
+	use := func(name string, span ast.Span) {
+		if _, ok := g.classes[name]; ok || g.symbols == nil {
+			return
+		}
+		file := g.symbols.File(name)
+		if _, ok := opts.Modules[file]; !ok {
+			return
+		}
+		if !imported[file] {
+			g.warnf(span, codeMissingImport, "widget %s is declared in %s, which is not imported", name, file)
+		}
+		if names[file] == nil {
+			names[file] = make(map[string]bool)
+		}
+		names[file][jsIdent(name)] = true
+	}
+	ast.Inspect(unit, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.WidgetNode:
+			use(n.Name, n.Span)
+		case *ast.Call:
+			if n.Widget != nil {
+				use(n.Widget.Name, n.Widget.Span)
+			}
+		}
+		return true
+	})
+
+	var imports []Import
+	for file, set := range names {
+		imp := Import{File: file}
+		for name := range set {
+			imp.Names = append(imp.Names, name)
+		}
+		sort.Strings(imp.Names)
+		imports = append(imports, imp)
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].File < imports[j].File })
+	return imports
+}
+
+// resolveImport returns the Dart file that an import in file refers to.
+// It returns false for imports of the Dart SDK and of other packages,
+// which are provided by the runtime if at all.
+func (g *JSGenerator) resolveImport(file, uri, pkg string) (string, bool) {
+	switch {
+	case strings.HasPrefix(uri, "dart:"):
+		return "", false
+	case strings.HasPrefix(uri, "package:"):
+		rest, ok := strings.CutPrefix(uri, "package:"+pkg+"/")
+		if !ok || pkg == "" {
+			return "", false
+		}
+		return filepath.Join(g.sourceDir, "lib", filepath.FromSlash(rest)), true
+	}
+	return filepath.Join(filepath.Dir(file), filepath.FromSlash(uri)), true
+}
+
+// importPath returns the path that the module at from imports the module
+// at to by
+func importPath(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(from), to)
+	if err != nil {
+		return filepath.ToSlash(to)
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"compiler-go/pkg/ast"
+)
+
+// textStyleProps maps the TextStyle properties to the CSS properties the
+// runtime applies as a widget's style, with the unit of numeric values
+var textStyleProps = map[string]struct{ css, unit string }{
+	"color":           {"color", ""},
+	"backgroundColor": {"backgroundColor", ""},
+	"fontSize":        {"fontSize", "px"},
+	"fontWeight":      {"fontWeight", ""},
+	"fontStyle":       {"fontStyle", ""},
+	"fontFamily":      {"fontFamily", ""},
+	"letterSpacing":   {"letterSpacing", "px"},
+	"wordSpacing":     {"wordSpacing", "px"},
+	"height":          {"lineHeight", ""},
+	"decoration":      {"textDecoration", ""},
+	"decorationColor": {"textDecorationColor", ""},
+}
+
+// generateStyleValue converts a constructor call of a Flutter class that
+// describes a style rather than a widget, such as TextStyle or Color, to
+// the value the runtime applies. It reports false for other classes.
+func (g *JSGenerator) generateStyleValue(node *ast.WidgetNode) (string, bool) {
+	switch node.Name {
+	case "TextStyle":
+		return g.generateTextStyle(node), true
+	case "ThemeData", "ColorScheme", "ColorScheme_fromSeed":
+		// The runtime reads the theme's colors from its properties
+		return g.generateProps(node), true
+	case "Color", "Color_fromRGBO", "Color_fromARGB":
+		return g.generateColor(node), true
+	}
+	return "", false
+}
+
+// generateTextStyle converts a TextStyle to an object of CSS properties
+func (g *JSGenerator) generateTextStyle(node *ast.WidgetNode) string {
+	var props []string
+	for _, name := range node.PropertyNames() {
+		value := node.Properties[name]
+		prop, ok := textStyleProps[name]
+		if !ok {
+			g.unsupported(value, "TextStyle property "+name)
+			continue
+		}
+		props = append(props, jsProp(prop.css, g.generateLength(value, prop.unit)))
+	}
+	return jsObject(props)
+}
+
+// generateLength converts a value of a CSS property whose numbers take
+// unit, if any
+func (g *JSGenerator) generateLength(value ast.Value, unit string) string {
+	if unit == "" {
+		return g.generateValue(value)
+	}
+	if number, ok := value.(*ast.NumberValue); ok {
+		return jsString(fmt.Sprintf("%v%s", number.Value, unit))
+	}
+	return "`${" + g.generateValue(value) + "}" + unit + "`"
+}
+
+// generateColor converts a Color constructor call to a CSS color
+func (g *JSGenerator) generateColor(node *ast.WidgetNode) string {
+	args := node.Arguments
+	switch {
+	case node.Name == "Color" && len(args) == 1:
+		number, ok := args[0].(*ast.NumberValue)
+		if !ok {
+			break
+		}
+		argb := uint32(number.Value)
+		a, r, gr, b := argb>>24, argb>>16&0xff, argb>>8&0xff, argb&0xff
+		if a == 0xff {
+			return jsString(fmt.Sprintf("#%02x%02x%02x", r, gr, b))
+		}
+		return jsString(fmt.Sprintf("rgba(%d, %d, %d, %v)", r, gr, b, roundOpacity(float64(a)/255)))
+	case node.Name == "Color_fromRGBO" && len(args) == 4:
+		return g.generateRGBA(args[0], args[1], args[2], args[3], false)
+	case node.Name == "Color_fromARGB" && len(args) == 4:
+		return g.generateRGBA(args[1], args[2], args[3], args[0], true)
+	}
+	g.unsupported(node, strings.ReplaceAll(node.Name, "_", ".")+" with these arguments")
+	return "undefined"
+}
+
+// generateRGBA converts the channels of a color to a CSS rgba() color. The
+// opacity is an alpha value from 0 to 255 if alpha is set.
+func (g *JSGenerator) generateRGBA(r, gr, b, opacity ast.Value, alpha bool) string {
+	channels := []ast.Value{r, gr, b, opacity}
+	numbers := make([]float64, len(channels))
+	literal := true
+	for i, channel := range channels {
+		number, ok := channel.(*ast.NumberValue)
+		if !ok {
+			literal = false
+			break
+		}
+		numbers[i] = number.Value
+	}
+	if literal {
+		if alpha {
+			numbers[3] = roundOpacity(numbers[3] / 255)
+		}
+		return jsString(fmt.Sprintf("rgba(%v, %v, %v, %v)", numbers[0], numbers[1], numbers[2], numbers[3]))
+	}
+
+	codes := make([]string, len(channels))
+	for i, channel := range channels {
+		codes[i] = g.generateValue(channel)
+	}
+	if alpha {
+		codes[3] = "(" + codes[3] + ") / 255"
+	}
+	return fmt.Sprintf("`rgba(${%s}, ${%s}, ${%s}, ${%s})`", codes[0], codes[1], codes[2], codes[3])
+}
+
+// roundOpacity rounds an opacity to three decimals
+func roundOpacity(opacity float64) float64 {
+	return float64(int(opacity*1000+0.5)) / 1000
+}
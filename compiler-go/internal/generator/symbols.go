@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"compiler-go/internal/diag"
+	"compiler-go/pkg/ast"
+)
+
+// builtinWidgets lists the widgets implemented by the FlutterUI runtime
+var builtinWidgets = map[string]bool{
+	"Text":                 true,
+	"Center":               true,
+	"SizedBox":             true,
+	"ElevatedButton":       true,
+	"Container":            true,
+	"Row":                  true,
+	"Column":               true,
+	"MaterialApp":          true,
+	"Scaffold":             true,
+	"AppBar":               true,
+	"FloatingActionButton": true,
+	"Icon":                 true,
+	"Link":                 true,
+	"GestureDetector":      true,
+	"InkWell":              true,
+	"TextField":            true,
+}
+
+// SymbolTable records the widget classes declared across a project so
+// that a widget is treated as user-defined whatever its name
+type SymbolTable struct {
+	classes map[string]*ast.WidgetClass
+	files   map[string]string
+}
+
+// NewSymbolTable creates an empty symbol table
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		classes: make(map[string]*ast.WidgetClass),
+		files:   make(map[string]string),
+	}
+}
+
+// AddUnit adds every widget class declared in a compilation unit. A class
+// that is already declared in another file is reported and ignored.
+func (t *SymbolTable) AddUnit(unit *ast.CompilationUnit) diag.List {
+	var diags diag.List
+	for _, class := range unit.Classes {
+		if file, ok := t.files[class.Name]; ok && file != unit.File {
+			diags = append(diags, diag.Warningf(class.Span, codeDuplicateWidget,
+				"widget %s is already declared in %s", class.Name, file))
+			continue
+		}
+		t.classes[class.Name] = class
+		t.files[class.Name] = unit.File
+	}
+	return diags
+}
+
+// Lookup returns the declaration of a user-defined widget
+func (t *SymbolTable) Lookup(name string) (*ast.WidgetClass, bool) {
+	class, ok := t.classes[name]
+	return class, ok
+}
+
+// File returns the file that declares a user-defined widget
+func (t *SymbolTable) File(name string) string {
+	return t.files[name]
+}
+
+// IsBuiltin reports whether a widget is provided by the runtime
+func IsBuiltin(name string) bool {
+	return builtinWidgets[name]
+}
@@ -0,0 +1,14 @@
+import 'package:flutter/material.dart';
+import 'label.dart';
+
+class CountBadge extends StatelessWidget {
+  final int count;
+
+  const CountBadge({super.key, this.count = 0});
+
+  @override
+  Widget build(BuildContext context) {
+    final label = count == 1 ? '1 time' : '$count times';
+    return Row(children: [Icon(Icons.add, size: 16), Label(label)]);
+  }
+}
@@ -0,0 +1,17 @@
+import { FlutterUI } from './vortex-runtime.js';
+import { Label } from './label.js';
+
+// Generated from Flutter
+export class CountBadge extends FlutterUI {
+  constructor(props = {}, children = []) {
+    super();
+    this.props = {count: 0, ...props};
+    this.children = children;
+    this.state = {};
+  }
+
+  buildUI() {
+    const label = this.props.count === 1 ? '1 time' : `${this.props.count} times`;
+    return this.Row({}, [this.Icon({...{size: 16}, icon: 'add'}), new Label({text: label}, [])]);
+  }
+}
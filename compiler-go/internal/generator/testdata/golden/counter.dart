@@ -0,0 +1,75 @@
+import 'package:flutter/material.dart';
+import 'count_badge.dart';
+
+void main() {
+  runApp(const CounterApp(title: 'Counter'));
+}
+
+class CounterApp extends StatelessWidget {
+  final String title;
+
+  const CounterApp({super.key, required this.title});
+
+  @override
+  Widget build(BuildContext context) {
+    return MaterialApp(
+      title: title,
+      debugShowCheckedModeBanner: false,
+      theme: ThemeData(primarySwatch: Colors.indigo, scaffoldBackgroundColor: Colors.grey[100]),
+      home: CounterPage(title: title, step: 2),
+    );
+  }
+}
+
+class CounterPage extends StatefulWidget {
+  final String title;
+  final int step;
+
+  const CounterPage({super.key, required this.title, this.step = 1});
+
+  @override
+  State<CounterPage> createState() => _CounterPageState();
+}
+
+class _CounterPageState extends State<CounterPage> {
+  int _count = 0;
+  String _name = '';
+
+  void _increment() {
+    _add(by: widget.step);
+  }
+
+  void _add({int by = 1, int times = 1}) {
+    setState(() {
+      _count = _clamp(_count + by * times);
+    });
+  }
+
+  int _clamp(int value, [int max = 99]) => value > max ? max : value;
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(title: Text(widget.title), elevation: 4, centerTitle: true),
+      body: Column(
+        mainAxisAlignment: MainAxisAlignment.center,
+        crossAxisAlignment: CrossAxisAlignment.stretch,
+        children: [
+          Text('Hello $_name, you pushed the button $_count times',
+              textAlign: TextAlign.center, key: null),
+          SizedBox(width: 120, height: 16.5),
+          CountBadge(count: _count),
+          TextField(obscureText: false, onChanged: (value) => setState(() => _name = value)),
+          Container(width: 200, height: 40, padding: 8, margin: 4, child: Text('It\'s a container')),
+        ],
+      ),
+      floatingActionButton: _count < 99
+          ? FloatingActionButton(
+              tooltip: 'Increment',
+              onPressed: _increment,
+              child: Icon(Icons.add, size: 24, color: Colors.white),
+            )
+          : null,
+    );
+  }
+}
@@ -0,0 +1,56 @@
+import { FlutterUI } from './vortex-runtime.js';
+import { CountBadge } from './count_badge.js';
+
+// Generated from Flutter
+export class CounterApp extends FlutterUI {
+  constructor(props = {}, children = []) {
+    super();
+    this.props = props;
+    this.children = children;
+    this.state = {};
+  }
+
+  buildUI() {
+    return this.MaterialApp({title: this.props.title, debugShowCheckedModeBanner: false, theme: {primarySwatch: '#3f51b5', scaffoldBackgroundColor: '#f5f5f5'}, home: new CounterPage({title: this.props.title, step: 2}, [])}, []);
+  }
+}
+
+export class CounterPage extends FlutterUI {
+  constructor(props = {}, children = []) {
+    super();
+    this.props = {step: 1, ...props};
+    this.children = children;
+    this.state = {_count: 0, _name: ''};
+  }
+
+  _increment() {
+    this._add({by: this.props.step});
+  }
+
+  _add({by = 1, times = 1} = {}) {
+    this.setState(() => {
+      this.state._count = this._clamp(this.state._count + by * times);
+    });
+  }
+
+  _clamp(value, max = 99) {
+    return value > max ? max : value;
+  }
+
+  buildUI() {
+    return this.Scaffold({...{appBar: this.AppBar({title: this.Text(this.props.title, {}), elevation: 4, centerTitle: true}, []), body: this.Column({...{mainAxisAlignment: 'center', crossAxisAlignment: 'stretch'}, children: [this.Text(`Hello ${this.state._name}, you pushed the button ${this.state._count} times`, {textAlign: 'center', key: null}), this.SizedBox({width: 120, height: 16.5}), new CountBadge({count: this.state._count}, []), this.TextField({obscureText: false, onChanged: (value) => this.setState(() => this.state._name = value)}, []), this.Container({width: 200, height: 40, padding: 8, margin: 4}, [this.Text('It\'s a container', {})])]})}, floatingActionButton: this.state._count < 99 ? this.FloatingActionButton({tooltip: 'Increment', onPressed: this._increment.bind(this)}, [this.Icon({...{size: 24, color: '#ffffff'}, icon: 'add'})]) : null}, []);
+  }
+}
+
+class App extends CounterApp {
+  constructor() {
+    super({title: 'Counter'});
+    this.isRootApp = true;
+  }
+}
+
+// Initialize the app
+document.addEventListener('DOMContentLoaded', () => {
+  window.app = new App();
+  window.app.init();
+});
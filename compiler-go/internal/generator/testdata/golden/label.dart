@@ -0,0 +1,22 @@
+import 'package:flutter/material.dart';
+
+class Label extends StatelessWidget {
+  final String text;
+
+  const Label(this.text, {super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Text(
+      text,
+      textAlign: TextAlign.center,
+      style: TextStyle(
+        fontSize: 12,
+        fontWeight: FontWeight.w600,
+        color: Colors.red.shade700,
+        backgroundColor: Color(0x80FFFFFF),
+        decoration: TextDecoration.underline,
+      ),
+    );
+  }
+}
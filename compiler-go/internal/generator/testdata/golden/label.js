@@ -0,0 +1,15 @@
+import { FlutterUI } from './vortex-runtime.js';
+
+// Generated from Flutter
+export class Label extends FlutterUI {
+  constructor(props = {}, children = []) {
+    super();
+    this.props = props;
+    this.children = children;
+    this.state = {};
+  }
+
+  buildUI() {
+    return this.Text(this.props.text, {textAlign: 'center', style: {fontSize: '12px', fontWeight: '600', color: '#d32f2f', backgroundColor: 'rgba(255, 255, 255, 0.502)', textDecoration: 'underline'}});
+  }
+}
@@ -0,0 +1,872 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"compiler-go/pkg/ast"
+)
+
+// pushScope opens a scope for local variables and parameters
+func (g *JSGenerator) pushScope(names ...string) {
+	scope := make(map[string]bool)
+	for _, name := range names {
+		scope[name] = true
+	}
+	g.scopes = append(g.scopes, scope)
+}
+
+func (g *JSGenerator) popScope() {
+	g.scopes = g.scopes[:len(g.scopes)-1]
+}
+
+func (g *JSGenerator) declareLocal(name string) {
+	if len(g.scopes) > 0 {
+		g.scopes[len(g.scopes)-1][name] = true
+	}
+}
+
+func (g *JSGenerator) isLocal(name string) bool {
+	for i := len(g.scopes) - 1; i >= 0; i-- {
+		if g.scopes[i][name] {
+			return true
+		}
+	}
+	return false
+}
+
+// stateClass returns the State class of the widget being generated
+func (g *JSGenerator) stateClass() *ast.StateClass {
+	if g.currentClass == nil {
+		return nil
+	}
+	return g.currentClass.State
+}
+
+// isStateField reports whether name is an instance field of the State
+// class being generated
+func (g *JSGenerator) isStateField(name string) bool {
+	state := g.stateClass()
+	if state == nil {
+		return false
+	}
+	for _, field := range state.Fields {
+		if field.Name == name && !field.Static {
+			return true
+		}
+	}
+	return false
+}
+
+// methods returns the methods of the widget being generated, which for a
+// stateful widget are declared by its State class
+func (g *JSGenerator) methods() []*ast.Method {
+	if g.currentClass == nil {
+		return nil
+	}
+	if state := g.stateClass(); state != nil {
+		return state.Methods
+	}
+	return g.currentClass.Methods
+}
+
+// isMethod reports whether name is a method of the widget being generated
+func (g *JSGenerator) isMethod(name string) bool {
+	for _, method := range g.methods() {
+		if method.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveMember resolves a member of the class being generated: state
+// fields are read from this.state, widget fields from this.props and
+// methods from the component itself. A method that is not called, such as
+// the tear-off in `onPressed: _increment`, is bound to the component.
+func (g *JSGenerator) resolveMember(name string, called bool) (string, bool) {
+	switch {
+	case g.isStateField(name):
+		return "this.state." + name, true
+	case g.isMethod(name) && called:
+		return "this." + name, true
+	case g.isMethod(name):
+		return fmt.Sprintf("this.%s.bind(this)", name), true
+	case g.isField(name):
+		return "this.props." + name, true
+	}
+	return "", false
+}
+
+// resolveName resolves a name used in a method body or widget property
+func (g *JSGenerator) resolveName(name string, called bool) (string, bool) {
+	if g.isLocal(name) {
+		return jsIdent(name), true
+	}
+	if js, ok := g.resolveMember(name, called); ok {
+		return js, true
+	}
+	switch name {
+	case "this":
+		return "this", true
+	case "widget":
+		// A State object reaches its widget's fields through widget
+		if g.stateClass() != nil {
+			return "this.props", true
+		}
+	case "setState":
+		return "this.setState", true
+	case "context":
+		// The component is the closest equivalent of a BuildContext
+		return "this", true
+	}
+	return "", false
+}
+
+// unsupported reports an expression or statement that cannot be
+// translated
+func (g *JSGenerator) unsupported(node ast.Node, what string) {
+	g.warnf(node.NodeSpan(), codeUnsupportedExpression, "%s is not supported and was left out", what)
+}
+
+// translateExpr converts a Dart expression to JavaScript
+func (g *JSGenerator) translateExpr(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.Literal:
+		switch x.Kind {
+		case ast.StringLiteral:
+			return jsString(x.Value)
+		case ast.NullLiteral:
+			return "null"
+		}
+		return x.Value
+	case *ast.Ident:
+		if js, ok := g.resolveName(x.Name, false); ok {
+			return js
+		}
+		if text, ok := staticReference(x); ok {
+			return g.generateReference(text)
+		}
+		if x.Name == "super" {
+			return x.Name
+		}
+		g.warnf(x.Span, codeUnresolvedIdentifier, "identifier %s cannot be resolved and was left out", x.Name)
+		return "undefined"
+	case *ast.StringInterpolation:
+		return g.translateInterpolation(x)
+	case *ast.Paren:
+		return "(" + g.translateExpr(x.X) + ")"
+	case *ast.Member:
+		return g.translateMember(x, false)
+	case *ast.Index:
+		if text, ok := staticReference(x); ok {
+			return g.generateReference(text)
+		}
+		return fmt.Sprintf("%s[%s]", g.translateExpr(x.X), g.translateExpr(x.Index))
+	case *ast.Call:
+		return g.translateCall(x)
+	case *ast.Unary:
+		switch {
+		case x.Op == "!" && x.Postfix:
+			// Null assertions have no runtime effect
+			return g.translateExpr(x.X)
+		case x.Postfix:
+			return g.operand(x.X, precPostfix, false) + x.Op
+		case x.Op == "await":
+			return "await " + g.operand(x.X, precPrefix, false)
+		}
+		// A nested prefix operator is parenthesized, since - -x would
+		// otherwise read as --x
+		return x.Op + g.operand(x.X, precPrefix, true)
+	case *ast.Binary:
+		switch x.Op {
+		case "as":
+			return g.translateExpr(x.X)
+		case "is", "is!":
+			g.unsupported(x, "type test")
+			return "undefined"
+		case "~/":
+			return fmt.Sprintf("Math.trunc(%s / %s)", g.operand(x.X, precMultiplicative, false), g.operand(x.Y, precMultiplicative, true))
+		}
+		op := x.Op
+		if op == "==" || op == "!=" {
+			op += "="
+		}
+		return fmt.Sprintf("%s %s %s", g.binaryOperand(x.X, op, false), op, g.binaryOperand(x.Y, op, true))
+	case *ast.Conditional:
+		return fmt.Sprintf("%s ? %s : %s", g.operand(x.Cond, precConditional+1, false), g.translateExpr(x.Then), g.translateExpr(x.Else))
+	case *ast.Assign:
+		target := g.translateExpr(x.Target)
+		if x.Op == "~/=" {
+			return fmt.Sprintf("%s = Math.trunc(%s / %s)", target, target, g.operand(x.Value, precMultiplicative, true))
+		}
+		return fmt.Sprintf("%s %s %s", target, x.Op, g.translateExpr(x.Value))
+	case *ast.FuncLit:
+		return g.translateFunc(x)
+	case *ast.ListLit:
+		var elements []string
+		for _, element := range x.Elements {
+			if _, raw := element.(*ast.RawExpr); raw {
+				g.unsupported(element, "collection element")
+				continue
+			}
+			elements = append(elements, g.translateExpr(element))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *ast.MapLit:
+		return g.translateMap(x)
+	case *ast.RawExpr:
+		g.unsupported(x, fmt.Sprintf("expression %q", x.Text))
+		return "undefined"
+	}
+	return "undefined"
+}
+
+// Precedence of JavaScript operators, from lowest to highest. Dart
+// orders some operators differently, e.g. & binds tighter than == in
+// Dart but looser in JavaScript, so operands are parenthesized by the
+// precedence of the JavaScript they translate to.
+const (
+	precAssign = iota + 1 // also arrow functions
+	precConditional
+	precLogicalOr // also ??, which cannot be mixed with || or && unparenthesized
+	precLogicalAnd
+	precBitwiseOr
+	precBitwiseXor
+	precBitwiseAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+	precPrefix
+	precPostfix
+	precPrimary
+)
+
+// binaryPrecedence holds the precedence of the JavaScript binary
+// operators the translator emits
+var binaryPrecedence = map[string]int{
+	"??":  precLogicalOr,
+	"||":  precLogicalOr,
+	"&&":  precLogicalAnd,
+	"|":   precBitwiseOr,
+	"^":   precBitwiseXor,
+	"&":   precBitwiseAnd,
+	"===": precEquality,
+	"!==": precEquality,
+	"<":   precRelational,
+	">":   precRelational,
+	"<=":  precRelational,
+	">=":  precRelational,
+	"<<":  precShift,
+	">>":  precShift,
+	"+":   precAdditive,
+	"-":   precAdditive,
+	"*":   precMultiplicative,
+	"/":   precMultiplicative,
+	"%":   precMultiplicative,
+}
+
+// precedence returns the precedence of the JavaScript that e translates
+// to
+func precedence(e ast.Expr) int {
+	switch x := e.(type) {
+	case *ast.Binary:
+		switch x.Op {
+		case "as":
+			return precedence(x.X)
+		case "is", "is!", "~/":
+			return precPrimary
+		}
+		if prec, ok := binaryPrecedence[x.Op]; ok {
+			return prec
+		}
+		return precEquality // == and !=
+	case *ast.Unary:
+		switch {
+		case x.Op == "!" && x.Postfix:
+			return precedence(x.X)
+		case x.Postfix:
+			return precPostfix
+		}
+		return precPrefix
+	case *ast.Conditional:
+		return precConditional
+	case *ast.Assign, *ast.FuncLit:
+		return precAssign
+	}
+	return precPrimary
+}
+
+// operand translates an operand of an operator of precedence prec,
+// parenthesized unless it binds tighter. An operand on the left of a
+// left-associative operator may also bind as tightly, unless right is set.
+func (g *JSGenerator) operand(e ast.Expr, prec int, right bool) string {
+	js := g.translateExpr(e)
+	if p := precedence(e); p < prec || (p == prec && right) {
+		return "(" + js + ")"
+	}
+	return js
+}
+
+// binaryOperand translates an operand of the JavaScript binary operator
+// op. JavaScript rejects ?? mixed with || or && without parentheses.
+func (g *JSGenerator) binaryOperand(e ast.Expr, op string, right bool) string {
+	if x, ok := e.(*ast.Binary); ok && (op == "??") != (x.Op == "??") && (op == "??" || op == "||" || op == "&&") && (x.Op == "??" || x.Op == "||" || x.Op == "&&") {
+		return "(" + g.translateExpr(e) + ")"
+	}
+	return g.operand(e, binaryPrecedence[op], right)
+}
+
+// translateMember converts a member access, mapping the Dart collection
+// and string properties that differ from their JavaScript equivalents.
+// called is set when the member is the callee of a call.
+func (g *JSGenerator) translateMember(x *ast.Member, called bool) string {
+	if text, ok := staticReference(x); ok {
+		return g.generateReference(text)
+	}
+	if this, ok := x.X.(*ast.Ident); ok && this.Name == "this" {
+		if js, ok := g.resolveMember(x.Name, called); ok {
+			return js
+		}
+	}
+	target := g.translateExpr(x.X)
+	if !called && !isComponent(x.X) {
+		switch x.Name {
+		case "isEmpty":
+			return fmt.Sprintf("(%s.length === 0)", target)
+		case "isNotEmpty":
+			return fmt.Sprintf("(%s.length > 0)", target)
+		case "first":
+			return target + "[0]"
+		case "last":
+			return target + ".at(-1)"
+		case "reversed":
+			return fmt.Sprintf("[...%s].reverse()", target)
+		case "keys":
+			return fmt.Sprintf("Object.keys(%s)", target)
+		case "values":
+			return fmt.Sprintf("Object.values(%s)", target)
+		}
+	}
+	if x.NullAware {
+		return target + "?." + x.Name
+	}
+	return target + "." + x.Name
+}
+
+// translateCall converts a function, method or constructor call
+func (g *JSGenerator) translateCall(x *ast.Call) string {
+	if js, ok := g.translateNavigator(x); ok {
+		return js
+	}
+	if x.Widget != nil {
+		_, isClass := g.lookupClass(x.Widget.Name)
+		if _, member := x.Fun.(*ast.Member); !member || isClass || IsBuiltin(x.Widget.Name) {
+			return g.generateWidgetCode(x.Widget)
+		}
+		g.unsupported(x, fmt.Sprintf("call to %s", strings.ReplaceAll(x.Widget.Name, "_", ".")))
+		return "undefined"
+	}
+
+	if member, ok := x.Fun.(*ast.Member); ok && !isComponent(member.X) {
+		return g.translateMethodCall(x, member)
+	}
+
+	callee, ok := g.translateCallee(x)
+	if !ok {
+		return "undefined"
+	}
+	var args, named []string
+	for _, arg := range x.Args {
+		if arg.Name == "" {
+			args = append(args, g.translateExpr(arg.Value))
+		} else {
+			named = append(named, jsProp(arg.Name, g.translateExpr(arg.Value)))
+		}
+	}
+	// Named arguments are passed as a trailing object
+	if len(named) > 0 {
+		args = append(args, jsObject(named))
+	}
+	return fmt.Sprintf("%s(%s)", callee, strings.Join(args, ", "))
+}
+
+// coreMethods maps the methods of the Dart core library types that have
+// a JavaScript counterpart of a different name; the name of the others is
+// kept
+var coreMethods = map[string]string{
+	// Iterable and List
+	"where":      "filter",
+	"any":        "some",
+	"every":      "every",
+	"firstWhere": "find",
+	"indexWhere": "findIndex",
+	"expand":     "flatMap",
+	"contains":   "includes",
+	"map":        "map",
+	"forEach":    "forEach",
+	"join":       "join",
+	"indexOf":    "indexOf",
+	"sort":       "sort",
+
+	// String
+	"substring":           "substring",
+	"toUpperCase":         "toUpperCase",
+	"toLowerCase":         "toLowerCase",
+	"trim":                "trim",
+	"trimLeft":            "trimStart",
+	"trimRight":           "trimEnd",
+	"split":               "split",
+	"startsWith":          "startsWith",
+	"endsWith":            "endsWith",
+	"replaceAll":          "replaceAll",
+	"padLeft":             "padStart",
+	"padRight":            "padEnd",
+	"lastIndexOf":         "lastIndexOf",
+	"codeUnitAt":          "charCodeAt",
+	"toStringAsFixed":     "toFixed",
+	"toStringAsPrecision": "toPrecision",
+
+	// Future
+	"then":         "then",
+	"catchError":   "catch",
+	"whenComplete": "finally",
+}
+
+// translateMethodCall converts a call of a method of a value other than
+// the component, which the translator assumes to be of a Dart core library
+// type. Methods without a JavaScript counterpart are reported.
+func (g *JSGenerator) translateMethodCall(x *ast.Call, member *ast.Member) string {
+	var args []string
+	for _, arg := range x.Args {
+		if arg.Name != "" {
+			g.unsupported(x, fmt.Sprintf("named argument %s of %s", arg.Name, member.Name))
+			return "undefined"
+		}
+		args = append(args, g.translateExpr(arg.Value))
+	}
+	if number, ok := member.X.(*ast.Ident); ok && len(args) == 1 && (member.Name == "parse" || member.Name == "tryParse") {
+		switch number.Name {
+		case "int":
+			return fmt.Sprintf("parseInt(%s, 10)", args[0])
+		case "double", "num":
+			return fmt.Sprintf("Number(%s)", args[0])
+		}
+	}
+	target := g.translateExpr(member.X)
+	dot := "."
+	if member.NullAware {
+		dot = "?."
+	}
+	if name, ok := coreMethods[member.Name]; ok {
+		return fmt.Sprintf("%s%s%s(%s)", target, dot, name, strings.Join(args, ", "))
+	}
+
+	switch n := len(args); {
+	case member.Name == "call" && member.NullAware:
+		return fmt.Sprintf("%s?.(%s)", target, strings.Join(args, ", "))
+	case member.Name == "call":
+		return fmt.Sprintf("%s(%s)", target, strings.Join(args, ", "))
+	case n == 0:
+		switch member.Name {
+		case "toString":
+			return fmt.Sprintf("String(%s)", target)
+		case "toList":
+			return fmt.Sprintf("Array.from(%s)", target)
+		case "toSet":
+			return fmt.Sprintf("new Set(%s)", target)
+		case "toInt", "truncate":
+			return fmt.Sprintf("Math.trunc(%s)", target)
+		case "toDouble":
+			return fmt.Sprintf("Number(%s)", target)
+		case "round", "floor", "ceil", "abs":
+			return fmt.Sprintf("Math.%s(%s)", member.Name, target)
+		case "removeLast":
+			return target + ".pop()"
+		case "clear":
+			return target + ".splice(0)"
+		}
+	case n == 1:
+		switch member.Name {
+		case "add":
+			return fmt.Sprintf("%s.push(%s)", target, args[0])
+		case "addAll":
+			return fmt.Sprintf("%s.push(...%s)", target, args[0])
+		case "removeAt":
+			return fmt.Sprintf("%s.splice(%s, 1)[0]", target, args[0])
+		case "skip", "sublist":
+			return fmt.Sprintf("%s.slice(%s)", target, args[0])
+		case "take":
+			return fmt.Sprintf("%s.slice(0, %s)", target, args[0])
+		case "reduce":
+			return fmt.Sprintf("%s.reduce(%s)", target, args[0])
+		case "containsKey":
+			return fmt.Sprintf("Object.hasOwn(%s, %s)", target, args[0])
+		}
+	case n == 2:
+		switch member.Name {
+		case "insert":
+			return fmt.Sprintf("%s.splice(%s, 0, %s)", target, args[0], args[1])
+		case "sublist":
+			return fmt.Sprintf("%s.slice(%s, %s)", target, args[0], args[1])
+		case "fold":
+			return fmt.Sprintf("%s.reduce(%s, %s)", target, args[1], args[0])
+		case "clamp":
+			return fmt.Sprintf("Math.min(Math.max(%s, %s), %s)", target, args[0], args[1])
+		}
+	}
+	g.unsupported(x, fmt.Sprintf("call to %s", member.Name))
+	return "undefined"
+}
+
+// isComponent reports whether e refers to the component or its widget,
+// whose members are the widget's own
+func isComponent(e ast.Expr) bool {
+	ident, ok := e.(*ast.Ident)
+	return ok && (ident.Name == "this" || ident.Name == "widget" || ident.Name == "super")
+}
+
+// translateCallee converts the function called by a call expression,
+// reporting a call to a function that cannot be resolved
+func (g *JSGenerator) translateCallee(x *ast.Call) (string, bool) {
+	switch fun := x.Fun.(type) {
+	case *ast.Ident:
+		if js, ok := g.resolveName(fun.Name, true); ok {
+			return js, true
+		}
+		switch fun.Name {
+		case "print", "debugPrint":
+			return "console.log", true
+		}
+		g.unsupported(x, fmt.Sprintf("call to %s", fun.Name))
+		return "", false
+	case *ast.Member:
+		return g.translateMember(fun, true), true
+	}
+	return g.translateExpr(x.Fun), true
+}
+
+// translateNavigator converts Navigator.push, Navigator.pop and their
+// Navigator.of(context) forms to the runtime's page stack
+func (g *JSGenerator) translateNavigator(x *ast.Call) (string, bool) {
+	member, ok := x.Fun.(*ast.Member)
+	if !ok {
+		return "", false
+	}
+	args := x.Args
+	switch target := member.X.(type) {
+	case *ast.Ident:
+		if target.Name != "Navigator" {
+			return "", false
+		}
+		// The first argument is the BuildContext
+		if len(args) > 0 {
+			args = args[1:]
+		}
+	case *ast.Call:
+		of, ok := target.Fun.(*ast.Member)
+		if !ok || of.Name != "of" {
+			return "", false
+		}
+		if navigator, ok := of.X.(*ast.Ident); !ok || navigator.Name != "Navigator" {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	switch member.Name {
+	case "push", "pushReplacement":
+		if len(args) == 1 {
+			if page, ok := g.routePage(args[0].Value); ok {
+				if member.Name == "pushReplacement" {
+					return fmt.Sprintf("this.navigatorPush(%s, true)", page), true
+				}
+				return fmt.Sprintf("this.navigatorPush(%s)", page), true
+			}
+		}
+	case "pop", "maybePop":
+		return "this.navigatorPop()", true
+	case "pushNamed":
+		if len(args) > 0 {
+			return fmt.Sprintf("this.navigate(%s)", g.translateExpr(args[0].Value)), true
+		}
+	}
+	g.unsupported(x, fmt.Sprintf("call to Navigator.%s", member.Name))
+	return "undefined", true
+}
+
+// routePage returns the page built by a MaterialPageRoute or
+// CupertinoPageRoute passed to Navigator.push
+func (g *JSGenerator) routePage(route ast.Expr) (string, bool) {
+	call, ok := route.(*ast.Call)
+	if !ok || call.Widget == nil || (call.Widget.Name != "MaterialPageRoute" && call.Widget.Name != "CupertinoPageRoute") {
+		return "", false
+	}
+	for _, arg := range call.Args {
+		builder, ok := arg.Value.(*ast.FuncLit)
+		if arg.Name != "builder" || !ok {
+			continue
+		}
+		// The builder's context parameter is the component itself
+		if builder.Result != nil {
+			return g.translateExpr(builder.Result), true
+		}
+		return fmt.Sprintf("(%s)(this)", g.translateFunc(builder)), true
+	}
+	return "", false
+}
+
+// translateInterpolation converts an interpolated string to a template
+// literal
+func (g *JSGenerator) translateInterpolation(x *ast.StringInterpolation) string {
+	var b strings.Builder
+	b.WriteString("`")
+	for _, part := range x.Parts {
+		if literal, ok := part.(*ast.Literal); ok && literal.Kind == ast.StringLiteral {
+			b.WriteString(jsTemplateText(literal.Value))
+			continue
+		}
+		b.WriteString("${" + g.translateExpr(part) + "}")
+	}
+	b.WriteString("`")
+	return b.String()
+}
+
+// translateMap converts a map literal to an object and a set literal to
+// a Set
+func (g *JSGenerator) translateMap(x *ast.MapLit) string {
+	var entries []string
+	isSet := len(x.Entries) > 0
+	for _, entry := range x.Entries {
+		if _, raw := entry.Value.(*ast.RawExpr); raw {
+			g.unsupported(entry.Value, "collection element")
+			continue
+		}
+		if entry.Key == nil {
+			entries = append(entries, g.translateExpr(entry.Value))
+			continue
+		}
+		isSet = false
+		key := g.translateExpr(entry.Key)
+		if literal, ok := entry.Key.(*ast.Literal); !ok || literal.Kind != ast.StringLiteral {
+			key = "[" + key + "]"
+		}
+		entries = append(entries, key+": "+g.translateExpr(entry.Value))
+	}
+	if isSet {
+		return "new Set([" + strings.Join(entries, ", ") + "])"
+	}
+	return jsObject(entries)
+}
+
+// translateFunc converts a function literal to an arrow function
+func (g *JSGenerator) translateFunc(fn *ast.FuncLit) string {
+	params := g.translateParams(fn.Params)
+	g.pushScope(paramNames(fn.Params)...)
+	defer g.popScope()
+
+	async := ""
+	if fn.Async {
+		async = "async "
+	}
+	if fn.Result != nil {
+		result := g.translateExpr(fn.Result)
+		if _, ok := fn.Result.(*ast.MapLit); ok {
+			result = "(" + result + ")"
+		}
+		return fmt.Sprintf("%s%s(%s) => %s", g.mark(fn.Span), async, params, result)
+	}
+	return fmt.Sprintf("%s%s(%s) => %s", g.mark(fn.Span), async, params, g.translateBlock(fn.Body.Stmts))
+}
+
+// translateBlock converts statements to a brace-delimited JavaScript
+// block indented one level deeper than the current statement
+func (g *JSGenerator) translateBlock(stmts []ast.Stmt) string {
+	outer := g.indent
+	g.indent += "  "
+	g.pushScope()
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, stmt := range stmts {
+		b.WriteString(g.translateStmt(stmt))
+	}
+	g.popScope()
+	g.indent = outer
+	b.WriteString(outer + "}")
+	return b.String()
+}
+
+// translateBody converts the body of an if statement or loop to a block
+func (g *JSGenerator) translateBody(stmt ast.Stmt) string {
+	if block, ok := stmt.(*ast.Block); ok {
+		return g.translateBlock(block.Stmts)
+	}
+	return g.translateBlock([]ast.Stmt{stmt})
+}
+
+// translateStmt converts a statement to indented JavaScript lines
+func (g *JSGenerator) translateStmt(s ast.Stmt) string {
+	indent := g.indent + g.mark(s.NodeSpan())
+	switch x := s.(type) {
+	case *ast.ExprStmt:
+		return indent + g.translateExpr(x.X) + ";\n"
+	case *ast.VarDecl:
+		return indent + g.translateVarDecl(x) + ";\n"
+	case *ast.Return:
+		if x.Value == nil {
+			return indent + "return;\n"
+		}
+		return indent + "return " + g.translateExpr(x.Value) + ";\n"
+	case *ast.If:
+		code := indent + fmt.Sprintf("if (%s) %s", g.translateExpr(x.Cond), g.translateBody(x.Then))
+		if x.Else != nil {
+			if elseIf, ok := x.Else.(*ast.If); ok {
+				code += " else " + strings.TrimPrefix(g.translateStmt(elseIf), g.indent)
+				return code
+			}
+			code += " else " + g.translateBody(x.Else)
+		}
+		return code + "\n"
+	case *ast.Block:
+		return indent + g.translateBlock(x.Stmts) + "\n"
+	case *ast.For:
+		g.pushScope()
+		defer g.popScope()
+		init := ""
+		switch initStmt := x.Init.(type) {
+		case *ast.VarDecl:
+			init = g.translateVarDecl(initStmt)
+		case *ast.ExprStmt:
+			init = g.translateExpr(initStmt.X)
+		}
+		cond := ""
+		if x.Cond != nil {
+			cond = g.translateExpr(x.Cond)
+		}
+		var updates []string
+		for _, update := range x.Update {
+			updates = append(updates, g.translateExpr(update))
+		}
+		return indent + fmt.Sprintf("for (%s; %s; %s) %s\n", init, cond, strings.Join(updates, ", "), g.translateBody(x.Body))
+	case *ast.ForIn:
+		iterable := g.translateExpr(x.Iterable)
+		g.pushScope(x.Name)
+		defer g.popScope()
+		return indent + fmt.Sprintf("for (const %s of %s) %s\n", jsIdent(x.Name), iterable, g.translateBody(x.Body))
+	case *ast.While:
+		return indent + fmt.Sprintf("while (%s) %s\n", g.translateExpr(x.Cond), g.translateBody(x.Body))
+	case *ast.Branch:
+		return indent + x.Keyword + ";\n"
+	case *ast.RawStmt:
+		g.unsupported(x, fmt.Sprintf("%s statement", strings.Fields(x.Text)[0]))
+	}
+	return ""
+}
+
+// translateVarDecl converts a local variable declaration without the
+// trailing semicolon. Variables that are assigned later use let.
+func (g *JSGenerator) translateVarDecl(decl *ast.VarDecl) string {
+	keyword := "let"
+	var vars []string
+	for _, spec := range decl.Vars {
+		if spec.Value == nil {
+			vars = append(vars, jsIdent(spec.Name))
+		} else {
+			vars = append(vars, fmt.Sprintf("%s = %s", jsIdent(spec.Name), g.translateExpr(spec.Value)))
+		}
+		g.declareLocal(spec.Name)
+	}
+	if decl.Final && len(decl.Vars) > 0 && decl.Vars[len(decl.Vars)-1].Value != nil {
+		keyword = "const"
+	}
+	return keyword + " " + strings.Join(vars, ", ")
+}
+
+// translateMethod converts a State method to a JavaScript class method
+func (g *JSGenerator) translateMethod(method *ast.Method) string {
+	fn := method.Func
+	params := g.translateParams(fn.Params)
+	g.pushScope(paramNames(fn.Params)...)
+	defer g.popScope()
+
+	g.indent = "  "
+	defer func() { g.indent = "" }()
+
+	async := ""
+	if fn.Async {
+		async = "async "
+	}
+	stmts := []ast.Stmt{}
+	if fn.Body != nil {
+		stmts = fn.Body.Stmts
+	} else {
+		stmts = append(stmts, &ast.Return{Value: fn.Result, Span: fn.Result.NodeSpan()})
+	}
+	return fmt.Sprintf("  %s%s%s(%s) %s\n", g.mark(method.Span), async, method.Name, params, g.translateBlock(stmts))
+}
+
+// translateParams converts the parameter list of a function. Named
+// parameters are destructured from the trailing object that calls pass
+// named arguments in, which may be left out, and defaults of optional
+// parameters become JavaScript defaults.
+func (g *JSGenerator) translateParams(params []*ast.Parameter) string {
+	var positional, named []string
+	for _, param := range params {
+		code := jsIdent(param.Name)
+		if param.Named && jsKey(param.Name) != code {
+			code = jsKey(param.Name) + ": " + code
+		}
+		if param.Default != nil {
+			code += " = " + g.translateExpr(param.Default)
+		}
+		if param.Named {
+			named = append(named, code)
+		} else {
+			positional = append(positional, code)
+		}
+	}
+	if len(named) > 0 {
+		positional = append(positional, "{"+strings.Join(named, ", ")+"} = {}")
+	}
+	return strings.Join(positional, ", ")
+}
+
+// paramNames returns the names of the parameters of a function
+func paramNames(params []*ast.Parameter) []string {
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Name
+	}
+	return names
+}
+
+// staticReference returns the source form of a reference to a static
+// member or enum value, such as Colors.blue or Colors.grey[300]
+func staticReference(e ast.Expr) (string, bool) {
+	switch x := e.(type) {
+	case *ast.Ident:
+		if isUpper(x.Name) {
+			return x.Name, true
+		}
+	case *ast.Member:
+		if base, ok := staticReference(x.X); ok && !x.NullAware {
+			return base + "." + x.Name, true
+		}
+	case *ast.Index:
+		literal, ok := x.Index.(*ast.Literal)
+		if base, isStatic := staticReference(x.X); isStatic && ok && literal.Kind == ast.NumberLiteral {
+			return fmt.Sprintf("%s[%s]", base, literal.Value), true
+		}
+	}
+	return "", false
+}
+
+// isUpper reports whether name starts with an upper-case letter, ignoring
+// the leading underscores of library-private names
+func isUpper(name string) bool {
+	name = strings.TrimLeft(name, "_")
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"compiler-go/internal/diag"
+	"compiler-go/internal/parser"
+)
+
+// translate compiles a Dart expression over the variables a to e inside
+// a method and returns its JavaScript, failing on any diagnostic
+func translate(t *testing.T, expr string) string {
+	t.Helper()
+	js, diags := translateWithDiagnostics(t, expr)
+	if len(diags) > 0 {
+		t.Fatalf("%s: unexpected diagnostics: %v", expr, diags)
+	}
+	return js
+}
+
+// translateWithDiagnostics is translate for expressions expected to be
+// reported
+func translateWithDiagnostics(t *testing.T, expr string) (string, diag.List) {
+	t.Helper()
+	source := `
+class W extends StatelessWidget {
+  void f(a, b, c, d, e) {
+    print(` + expr + `);
+  }
+
+  @override
+  Widget build(BuildContext context) => Text('');
+}
+`
+	unit, diags := parser.NewParser().ParseUnit("w.dart", source)
+	if err := diags.Err(); err != nil {
+		t.Fatal(err)
+	}
+	g := NewJSGenerator()
+	g.SetSymbols(NewSymbolTable())
+	js, err := g.GenerateModule(unit, ModuleOptions{Path: "w.js", Runtime: RuntimeModule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, call, ok := strings.Cut(js, "console.log(")
+	if !ok {
+		t.Fatalf("%s: no call of console.log in\n%s", expr, js)
+	}
+	return strings.TrimSuffix(call[:strings.Index(call, "\n")], ");"), g.Diagnostics()
+}
+
+func TestTranslatePrecedence(t *testing.T) {
+	tests := []struct {
+		dart, js string
+	}{
+		{"a + b * c", "a + b * c"},
+		{"a * b + c", "a * b + c"},
+		{"a - b - c", "a - b - c"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"(a + b) * c", "(a + b) * c"},
+
+		// & binds tighter than == in Dart but looser in JavaScript
+		{"a & 1 == 0", "(a & 1) === 0"},
+		{"a == b & c", "a === (b & c)"},
+		{"a | b ^ c & d", "a | b ^ c & d"},
+		{"a < b == c < d", "a < b === c < d"},
+
+		// ?? cannot be mixed with || or && without parentheses
+		{"a ?? b > 0 || c", "a ?? (b > 0 || c)"},
+		{"a ?? b && c", "a ?? (b && c)"},
+		{"(a ?? b) || c", "(a ?? b) || c"},
+		{"a ?? b ?? c", "a ?? b ?? c"},
+		{"a || b && c", "a || b && c"},
+
+		{"a + b ~/ c", "a + Math.trunc(b / c)"},
+		{"(a + b) ~/ c", "Math.trunc((a + b) / c)"},
+		{"a ~/ b * c", "Math.trunc(a / b) * c"},
+		{"a ? b : c ? d : e", "a ? b : c ? d : e"},
+		{"a ?? b ? c : d", "a ?? b ? c : d"},
+		{"(a ? b : c) ? d : e", "(a ? b : c) ? d : e"},
+		{"a + b as int", "a + b"},
+		{"(a as int) * b", "(a) * b"},
+		{"- -a", "-(-a)"},
+		{"!a || b", "!a || b"},
+		{"a! + b", "a + b"},
+	}
+	for _, test := range tests {
+		if got := translate(t, test.dart); got != test.js {
+			t.Errorf("%s: got %s, want %s", test.dart, got, test.js)
+		}
+	}
+}
+
+func TestTranslateCoreMembers(t *testing.T) {
+	tests := []struct {
+		dart, js string
+	}{
+		{"a.toStringAsFixed(2)", "a.toFixed(2)"},
+		{"a.toString()", "String(a)"},
+		{"a.toInt()", "Math.trunc(a)"},
+		{"a.round()", "Math.round(a)"},
+		{"a.clamp(0, 10)", "Math.min(Math.max(a, 0), 10)"},
+		{"int.parse(a)", "parseInt(a, 10)"},
+		{"double.parse(a)", "Number(a)"},
+		{"a.isEmpty", "(a.length === 0)"},
+		{"a.isNotEmpty || b", "(a.length > 0) || b"},
+		{"a.length", "a.length"},
+		{"a.first", "a[0]"},
+		{"a.last", "a.at(-1)"},
+		{"a.reversed", "[...a].reverse()"},
+		{"a.add(b)", "a.push(b)"},
+		{"a.addAll(b)", "a.push(...b)"},
+		{"a.insert(0, b)", "a.splice(0, 0, b)"},
+		{"a.removeAt(b)", "a.splice(b, 1)[0]"},
+		{"a.contains(b)", "a.includes(b)"},
+		{"a.where((x) => x > 0).map((x) => x * 2).toList()", "Array.from(a.filter((x) => x > 0).map((x) => x * 2))"},
+		{"a.fold(0, (s, x) => s + x)", "a.reduce((s, x) => s + x, 0)"},
+		{"a.containsKey(b)", "Object.hasOwn(a, b)"},
+		{"a.keys.join(', ')", "Object.keys(a).join(', ')"},
+		{"a.padLeft(2, '0')", "a.padStart(2, '0')"},
+		{"a?.trim()", "a?.trim()"},
+		{"a?.call(b)", "a?.(b)"},
+		{"a.call()", "a()"},
+	}
+	for _, test := range tests {
+		if got := translate(t, test.dart); got != test.js {
+			t.Errorf("%s: got %s, want %s", test.dart, got, test.js)
+		}
+	}
+}
+
+func TestTranslateUnsupportedMethod(t *testing.T) {
+	for _, dart := range []string{"a.compareTo(b)", "a.toList(growable: false)"} {
+		js, diags := translateWithDiagnostics(t, dart)
+		if js != "undefined" {
+			t.Errorf("%s: got %s, want undefined", dart, js)
+		}
+		if len(diags) != 1 || diags[0].Code != codeUnsupportedExpression {
+			t.Errorf("%s: got diagnostics %v, want one %s", dart, diags, codeUnsupportedExpression)
+		}
+	}
+}
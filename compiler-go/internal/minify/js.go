@@ -0,0 +1,599 @@
+// Package minify shrinks the JavaScript the compiler emits. It is not a
+// general-purpose minifier: it relies on the emitted code terminating its
+// statements with semicolons and containing no regular expression
+// literals, labels or computed property names in parameter patterns.
+// Annotations delimited by NUL bytes, such as the marks of package
+// sourcemap, are kept before the token that follows them.
+package minify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JS removes the comments and the whitespace that the meaning of src does
+// not depend on and renames the parameters and local variables of its
+// functions to short names. Top-level declarations, classes, properties
+// and class members keep their names.
+func JS(src string) (string, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return "", err
+	}
+	m := &minifier{tokens: tokens}
+	m.matchBrackets()
+	m.buildScopes()
+	m.allocateNames()
+	return m.print(), nil
+}
+
+type tokenKind int
+
+const (
+	tokenIdent    tokenKind = iota // identifiers and keywords
+	tokenNumber                    // numeric literals
+	tokenString                    // quoted string literals
+	tokenTemplate                  // template literal text, `...` or parts split by ${ }
+	tokenPunct                     // punctuators
+)
+
+type token struct {
+	kind       tokenKind
+	text       string
+	newline    bool   // a line break precedes the token
+	opens      bool   // the token opens a bracket: ( [ { or a template's ${
+	closes     bool   // the token closes a bracket: ) ] } or a template's }
+	templated  bool   // a template part that continues after a ${ }
+	annotation string // the annotations before the token
+}
+
+// punctuators lists the multi-character punctuators, longest first
+var punctuators = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// tokenize splits src into tokens, dropping whitespace and comments
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	newline := false
+	// Number of braces open in each enclosing template substitution
+	var substitutions []int
+	annotation := ""
+	emit := func(tok token) {
+		tok.newline, tok.annotation = newline, annotation
+		newline, annotation = false, ""
+		tokens = append(tokens, tok)
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n' || c == '\r':
+			newline = true
+			i++
+		case c == ' ' || c == '\t' || c == '\f' || c == '\v':
+			i++
+		case c == 0:
+			end := strings.IndexByte(src[i+1:], 0)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated annotation")
+			}
+			annotation += src[i : i+end+2]
+			i += end + 2
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			if strings.ContainsAny(src[i:i+2+end], "\n\r") {
+				newline = true
+			}
+			i += end + 4
+		case c == '\'' || c == '"':
+			start := i
+			for i++; i < len(src) && src[i] != c; i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '\n' {
+					return nil, fmt.Errorf("unterminated string")
+				}
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			emit(token{kind: tokenString, text: src[start:i]})
+		case c == '`' || (c == '}' && len(substitutions) > 0 && substitutions[len(substitutions)-1] == 0):
+			// A template, or its text following a substitution
+			start := i
+			continued := c == '}'
+			if continued {
+				substitutions = substitutions[:len(substitutions)-1]
+			}
+			opens := false
+			for i++; i < len(src); i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '`' {
+					i++
+					break
+				} else if strings.HasPrefix(src[i:], "${") {
+					i += 2
+					opens = true
+					substitutions = append(substitutions, 0)
+					break
+				}
+			}
+			if i > len(src) || (!opens && src[i-1] != '`') {
+				return nil, fmt.Errorf("unterminated template literal")
+			}
+			emit(token{kind: tokenTemplate, text: src[start:i], opens: opens, closes: continued, templated: continued})
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			for i < len(src) && (isIdentChar(src[i]) || src[i] == '.' ||
+				((src[i] == '+' || src[i] == '-') && (src[i-1] == 'e' || src[i-1] == 'E'))) {
+				i++
+			}
+			emit(token{kind: tokenNumber, text: src[start:i]})
+		case isIdentChar(c):
+			start := i
+			for i < len(src) && isIdentChar(src[i]) {
+				i++
+			}
+			emit(token{kind: tokenIdent, text: src[start:i]})
+		default:
+			text := src[i : i+1]
+			for _, punct := range punctuators {
+				if strings.HasPrefix(src[i:], punct) {
+					text = punct
+					break
+				}
+			}
+			i += len(text)
+			tok := token{kind: tokenPunct, text: text}
+			switch text {
+			case "(", "[", "{":
+				tok.opens = true
+				if text == "{" && len(substitutions) > 0 {
+					substitutions[len(substitutions)-1]++
+				}
+			case ")", "]", "}":
+				tok.closes = true
+				if text == "}" && len(substitutions) > 0 {
+					substitutions[len(substitutions)-1]--
+				}
+			}
+			emit(tok)
+		}
+	}
+	if len(substitutions) > 0 {
+		return nil, fmt.Errorf("unterminated template literal")
+	}
+	return tokens, nil
+}
+
+// braceKind tells what a { opens
+type braceKind int
+
+const (
+	braceBlock     braceKind = iota // a statement block or function body
+	braceArrowBody                  // the body of an arrow function
+	braceObject                     // an object literal or pattern
+	braceClass                      // a class body
+)
+
+// scope is a function or block scope
+type scope struct {
+	parent *scope
+	end    int               // index of the token after the scope
+	names  map[string]string // new name of each declared name
+	order  []string          // declared names in order
+}
+
+func (s *scope) declare(name string) {
+	if _, ok := s.names[name]; !ok {
+		s.names[name] = ""
+		s.order = append(s.order, name)
+	}
+}
+
+type minifier struct {
+	tokens    []token
+	match     []int // index of the matching bracket of each bracket token
+	enclosing []int // index of the innermost bracket enclosing each token, or -1
+	braces    map[int]braceKind
+	scopes    []*scope // in order of their start
+	scopeAt   []*scope // innermost scope of each token, or nil
+}
+
+func (m *minifier) is(i int, text string) bool {
+	return i >= 0 && i < len(m.tokens) && m.tokens[i].kind != tokenString &&
+		m.tokens[i].kind != tokenTemplate && m.tokens[i].text == text
+}
+
+func (m *minifier) isIdent(i int) bool {
+	return i >= 0 && i < len(m.tokens) && m.tokens[i].kind == tokenIdent
+}
+
+// matchBrackets pairs the brackets and classifies the braces
+func (m *minifier) matchBrackets() {
+	m.match = make([]int, len(m.tokens))
+	m.enclosing = make([]int, len(m.tokens))
+	m.braces = make(map[int]braceKind)
+	var open []int
+	for i, tok := range m.tokens {
+		m.enclosing[i] = -1
+		if len(open) > 0 {
+			m.enclosing[i] = open[len(open)-1]
+		}
+		if tok.closes && len(open) > 0 {
+			start := open[len(open)-1]
+			open = open[:len(open)-1]
+			m.match[start], m.match[i] = i, start
+			if len(open) > 0 {
+				m.enclosing[i] = open[len(open)-1]
+			} else {
+				m.enclosing[i] = -1
+			}
+		}
+		if tok.opens {
+			if tok.text == "{" && tok.kind == tokenPunct {
+				m.braces[i] = m.braceKind(i)
+			}
+			open = append(open, i)
+		}
+	}
+}
+
+// braceKind classifies the { at index i by the token before it
+func (m *minifier) braceKind(i int) braceKind {
+	switch {
+	case i == 0:
+		return braceBlock
+	case m.is(i-1, "=>"):
+		return braceArrowBody
+	case m.is(i-1, ")"), m.is(i-1, "{"), m.is(i-1, "}"), m.is(i-1, ";"), m.is(i-1, "else"),
+		m.is(i-1, "try"), m.is(i-1, "catch"), m.is(i-1, "finally"), m.is(i-1, "do"):
+		return braceBlock
+	}
+	// A class header is class Name or class Name extends a.b.C
+	for j := i - 1; j >= 0 && (m.isIdent(j) || m.is(j, ".")); j-- {
+		if m.is(j, "class") {
+			return braceClass
+		}
+	}
+	return braceObject
+}
+
+// controlKeywords precede a parenthesized expression followed by a block
+var controlKeywords = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "with": true}
+
+// buildScopes finds the scopes and the names declared in each
+func (m *minifier) buildScopes() {
+	m.scopeAt = make([]*scope, len(m.tokens))
+	bodies := make(map[int]*scope) // function bodies, by the index of their {
+	var current *scope
+	open := func(end int) *scope {
+		s := &scope{parent: current, end: end, names: make(map[string]string)}
+		m.scopes = append(m.scopes, s)
+		current = s
+		return s
+	}
+
+	for i := 0; i < len(m.tokens); i++ {
+		for current != nil && i >= current.end {
+			current = current.parent
+		}
+		tok := m.tokens[i]
+		switch {
+		case m.is(i, "("):
+			end := m.match[i]
+			after := end + 1
+			switch {
+			case m.is(after, "=>"):
+				s := open(m.arrowEnd(after + 1))
+				m.declareParams(s, i, end)
+				if m.is(after+1, "{") {
+					bodies[after+1] = s
+				}
+			case m.is(after, "{") && (m.is(i-1, "catch") || (m.isIdent(i-1) && !controlKeywords[m.tokens[i-1].text])):
+				s := open(m.match[after] + 1)
+				m.declareParams(s, i, end)
+				bodies[after] = s
+			}
+		case tok.kind == tokenIdent && m.is(i+1, "=>") && !m.is(i-1, "."):
+			s := open(m.arrowEnd(i + 2))
+			s.declare(tok.text)
+			if m.is(i+2, "{") {
+				bodies[i+2] = s
+			}
+		case m.is(i, "{"):
+			kind := m.braces[i]
+			if _, ok := bodies[i]; !ok && (kind == braceBlock || kind == braceArrowBody) {
+				open(m.match[i] + 1)
+			}
+		case (m.is(i, "const") || m.is(i, "let") || m.is(i, "var")) && current != nil:
+			m.declareVars(current, i)
+		}
+		m.scopeAt[i] = current
+	}
+}
+
+// arrowEnd returns the index of the token after an arrow function body
+// starting at i
+func (m *minifier) arrowEnd(i int) int {
+	if m.is(i, "{") {
+		return m.match[i] + 1
+	}
+	for ; i < len(m.tokens); i++ {
+		switch {
+		case m.tokens[i].closes, m.is(i, ","), m.is(i, ";"):
+			return i
+		case m.tokens[i].opens:
+			i = m.skip(i)
+		}
+	}
+	return i
+}
+
+// skip returns the index of the token closing the bracket opened at i,
+// past every part of a template
+func (m *minifier) skip(i int) int {
+	i = m.match[i]
+	for m.tokens[i].opens {
+		i = m.match[i]
+	}
+	return i
+}
+
+// declareParams declares the parameters between the brackets at start
+// and end, including those in array and object patterns. In an object
+// pattern, a name followed by a colon is a property name and the binding
+// follows the colon.
+func (m *minifier) declareParams(s *scope, start, end int) {
+	for i := start + 1; i < end; i++ {
+		switch {
+		case m.is(i, "="):
+			// Skip the default value
+			level := m.enclosing[i]
+			for i+1 < end && !(m.is(i+1, ",") && m.enclosing[i+1] == level) &&
+				!(m.tokens[i+1].closes && m.match[i+1] == level) {
+				i++
+				if m.tokens[i].opens {
+					i = m.skip(i)
+				}
+			}
+		case m.isIdent(i) && m.is(i+1, ":"):
+			// A property name of an object pattern
+		case m.isIdent(i) && (m.is(i-1, "(") || m.is(i-1, ",") || m.is(i-1, "[") || m.is(i-1, "...") ||
+			m.is(i-1, "{") || m.is(i-1, ":")):
+			s.declare(m.tokens[i].text)
+		}
+	}
+}
+
+// declareVars declares the variables of the declaration whose keyword is
+// at index i: the names that follow the keyword or a comma outside any
+// bracket, up to the end of the declaration
+func (m *minifier) declareVars(s *scope, i int) {
+	for j := i + 1; j < len(m.tokens); j++ {
+		switch {
+		case m.tokens[j].closes, m.is(j, ";"), m.is(j, "of"), m.is(j, "in"):
+			return
+		case m.tokens[j].opens:
+			j = m.skip(j)
+		case m.isIdent(j) && (j == i+1 || m.is(j-1, ",")):
+			s.declare(m.tokens[j].text)
+		}
+	}
+}
+
+// allocateNames gives each declared name the shortest name not used by
+// the program and not given to a name of an enclosing scope
+func (m *minifier) allocateNames() {
+	reserved := make(map[string]bool)
+	for _, tok := range m.tokens {
+		if tok.kind == tokenIdent {
+			reserved[tok.text] = true
+		}
+	}
+	for word := range keywords {
+		reserved[word] = true
+	}
+	var names []string
+	next := 0 // index of the next short name to consider
+	nameAt := func(n int) string {
+		for ; len(names) <= n; next++ {
+			if name := shortName(next); !reserved[name] {
+				names = append(names, name)
+			}
+		}
+		return names[n]
+	}
+
+	base := make(map[*scope]int)
+	for _, s := range m.scopes {
+		start := 0
+		if s.parent != nil {
+			start = base[s.parent] + len(s.parent.order)
+		}
+		base[s] = start
+		for k, name := range s.order {
+			s.names[name] = nameAt(start + k)
+		}
+	}
+}
+
+// shortName returns the nth name in the sequence a, b, ..., Z, aa, ab, ...
+func shortName(n int) string {
+	const first = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_$"
+	const rest = first + "0123456789"
+	name := []byte{first[n%len(first)]}
+	for n /= len(first); n > 0; n /= len(rest) {
+		n--
+		name = append(name, rest[n%len(rest)])
+	}
+	return string(name)
+}
+
+// rename returns the text of the identifier at index i after renaming,
+// which for shorthand properties, and the shorthand bindings of object
+// patterns such as {by = 1}, includes the property name
+func (m *minifier) rename(i int) string {
+	tok := m.tokens[i]
+	if m.is(i-1, ".") || m.is(i-1, "?.") {
+		return tok.text
+	}
+	name := tok.text
+	for s := m.scopeAt[i]; s != nil; s = s.parent {
+		if renamed, ok := s.names[tok.text]; ok {
+			name = renamed
+			break
+		}
+	}
+	if open := m.enclosing[i]; open >= 0 && m.is(open, "{") {
+		switch m.braces[open] {
+		case braceClass:
+			return tok.text
+		case braceObject:
+			if m.is(i-1, "{") || m.is(i-1, ",") {
+				switch {
+				case m.is(i+1, ":") || m.is(i+1, "("):
+					return tok.text
+				case (m.is(i+1, ",") || m.is(i+1, "}") || m.is(i+1, "=")) && name != tok.text:
+					return tok.text + ":" + name
+				}
+			}
+		}
+	}
+	return name
+}
+
+// print writes the tokens with the separators they need
+func (m *minifier) print() string {
+	var b strings.Builder
+	prev := ""
+	for i, tok := range m.tokens {
+		text := tok.text
+		if tok.kind == tokenIdent {
+			text = m.rename(i)
+		}
+		if i > 0 {
+			switch {
+			case tok.newline && m.needsNewline(i):
+				b.WriteByte('\n')
+			case isIdentChar(prev[len(prev)-1]) && isIdentChar(text[0]),
+				(strings.HasSuffix(prev, "+") && text[0] == '+') || (strings.HasSuffix(prev, "-") && text[0] == '-'):
+				b.WriteByte(' ')
+			case m.tokens[i-1].kind == tokenNumber && text == "." && !strings.ContainsAny(prev, ".eExXbBoO"):
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(tok.annotation)
+		b.WriteString(text)
+		prev = text
+	}
+	return b.String()
+}
+
+// continuationKeywords cannot end an expression, so the line break after
+// one never ends a statement
+var continuationKeywords = map[string]bool{
+	"case": true, "class": true, "const": true, "delete": true, "do": true, "else": true,
+	"extends": true, "in": true, "instanceof": true, "let": true, "new": true, "of": true,
+	"typeof": true, "var": true, "void": true, "await": true,
+}
+
+// restrictedKeywords may not be followed by a line break without ending
+// the statement
+var restrictedKeywords = map[string]bool{
+	"return": true, "break": true, "continue": true, "throw": true, "yield": true, "async": true,
+}
+
+// needsNewline reports whether the line break before token i may end a
+// statement, by automatic semicolon insertion, and so must be kept
+func (m *minifier) needsNewline(i int) bool {
+	prev, tok := m.tokens[i-1], m.tokens[i]
+	switch {
+	case prev.kind == tokenIdent && restrictedKeywords[prev.text]:
+		return true
+	case tok.kind == tokenPunct && (tok.text == "++" || tok.text == "--"):
+		return true
+	}
+
+	// The previous token must be able to end an expression
+	switch prev.kind {
+	case tokenIdent:
+		if continuationKeywords[prev.text] {
+			return false
+		}
+	case tokenPunct:
+		switch prev.text {
+		case ")", "]":
+		case "++", "--":
+			// Only a postfix operator ends an expression, and it follows
+			// its operand on the same line
+			before := i - 2
+			if prev.newline || before < 0 || !(m.isIdent(before) || m.is(before, ")") || m.is(before, "]")) {
+				return false
+			}
+		case "}":
+			if kind, ok := m.braces[m.match[i-1]]; ok && (kind == braceBlock || kind == braceClass) {
+				return false
+			}
+		default:
+			return false
+		}
+	case tokenTemplate:
+		if prev.opens {
+			return false
+		}
+	}
+
+	// and the token must be unable to continue it
+	switch tok.kind {
+	case tokenIdent:
+		return !continuingKeywords[tok.text]
+	case tokenNumber, tokenString:
+		return true
+	case tokenTemplate:
+		return false
+	}
+	return tok.text == "{" || tok.text == "!" || tok.text == "~"
+}
+
+// continuingKeywords cannot start a statement
+var continuingKeywords = map[string]bool{
+	"catch": true, "else": true, "extends": true, "finally": true, "in": true, "instanceof": true, "of": true,
+}
+
+// keywords are the reserved words and names with special meaning that
+// renamed variables must not use
+var keywords = map[string]bool{
+	"await": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "enum": true, "export": true, "extends": true, "false": true,
+	"finally": true, "for": true, "function": true, "if": true, "implements": true,
+	"import": true, "in": true, "instanceof": true, "interface": true, "let": true,
+	"new": true, "null": true, "package": true, "private": true, "protected": true,
+	"public": true, "return": true, "static": true, "super": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "typeof": true, "var": true,
+	"void": true, "while": true, "with": true, "yield": true, "arguments": true,
+	"eval": true, "undefined": true, "NaN": true, "Infinity": true, "of": true,
+	"get": true, "set": true, "async": true, "as": true, "from": true,
+}
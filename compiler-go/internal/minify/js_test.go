@@ -0,0 +1,140 @@
+package minify
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"compiler-go/internal/generator"
+)
+
+func TestJS(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{
+			"comments and whitespace",
+			"// comment\nconst x = 1; /* block */\nconst y = 'a  b';\n",
+			"const x=1;const y='a  b';",
+		},
+		{
+			"parameters",
+			"function f(count, label = 'x') {\n  return count + label;\n}\n",
+			"function f(a,b='x'){return a+b;}",
+		},
+		{
+			"shorthand properties",
+			"function f(count, label) {\n  return {count, label: label, m() {}};\n}\n",
+			"function f(a,b){return{count:a,label:b,m(){}};}",
+		},
+		{
+			"object pattern",
+			"class A {\n  _add({by = 1, times = 1} = {}) {\n    return by * times;\n  }\n}\n",
+			"class A{_add({by:a=1,times:b=1}={}){return a*b;}}",
+		},
+		{
+			"object pattern with property names",
+			"class A {\n  m({default: default$, max = 2} = {}, [x, y]) {\n    return default$ + max + x + y;\n  }\n}\n",
+			"class A{m({default:a,max:b=2}={},[c,d]){return a+b+c+d;}}",
+		},
+		{
+			"shadowed pattern binding",
+			"function f(by) {\n  const g = ({by}) => by;\n  return g({by: by});\n}\n",
+			"function f(a){const b=({by:c})=>c;return b({by:a});}",
+		},
+		{
+			"members keep their names",
+			"class A {\n  m(x) {\n    return this.x + x.length;\n  }\n}\n",
+			"class A{m(a){return this.x+a.length;}}",
+		},
+		{
+			"line breaks that end statements",
+			"function f(a) {\n  let x = a\n  x++\n  return\n  x\n}\n",
+			"function f(b){let c=b\nc++\nreturn\nc}",
+		},
+		{
+			"line breaks that do not",
+			"function f(a) {\n  const x = a\n  ;[1, 2].forEach(print)\n  return x +\n    a\n}\n",
+			"function f(b){const c=b;[1,2].forEach(print)\nreturn c+b}",
+		},
+		{
+			"separators between operators",
+			"const a = 1 + +b, c = d - -e, g = 1 .toString();\n",
+			"const a=1+ +b,c=d- -e,g=1 .toString();",
+		},
+		{
+			"templates",
+			"function f(name) {\n  return `Hello ${name}, ${ {name}.name }`;\n}\n",
+			"function f(a){return`Hello ${a}, ${{name:a}.name}`;}",
+		},
+		{
+			"annotations",
+			"function f(a) {\n  \x00main.dart\x0112\x00return a;\n}\n",
+			"function f(b){\x00main.dart\x0112\x00return b;}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JS(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("JS(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJSGolden minifies the generated modules of the generator's golden
+// tests and the runtime, and has node check that the result still parses
+func TestJSGolden(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not found")
+	}
+	inputs, err := filepath.Glob("../generator/testdata/golden/*.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no golden outputs found")
+	}
+	sources := make(map[string]string)
+	for _, input := range inputs {
+		src, err := os.ReadFile(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[filepath.Base(input)] = string(src)
+	}
+	jsGenerator := generator.NewJSGenerator()
+	jsGenerator.SetSourceDir("../generator/testdata/golden")
+	runtime, err := jsGenerator.GenerateRuntime(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources["vortex-runtime.js"] = runtime
+
+	dir := t.TempDir()
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			got, err := JS(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) >= len(src) {
+				t.Errorf("minified %d bytes to %d", len(src), len(got))
+			}
+			path := filepath.Join(dir, strings.TrimSuffix(name, ".js")+".mjs")
+			if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := exec.Command(node, "--check", path).CombinedOutput(); err != nil {
+				t.Errorf("minified %s does not parse: %v\n%s", name, err, out)
+			}
+		})
+	}
+}
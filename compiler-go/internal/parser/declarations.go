@@ -0,0 +1,619 @@
+package parser
+
+import (
+	"compiler-go/internal/diag"
+	"compiler-go/pkg/ast"
+)
+
+// classDecl collects what the parser needs from a class declaration
+// before it is known whether the class is a widget, a State or neither
+type classDecl struct {
+	name       string
+	superclass string
+	superArgs  []string
+	fields     []*ast.Field
+	params     []*ast.Parameter
+	methods    []*ast.Method
+	build      *ast.WidgetTree
+	abstract   bool
+	nameSpan   ast.Span
+	span       ast.Span
+}
+
+// classModifiers may precede the class keyword
+var classModifiers = map[string]bool{
+	"abstract":  true,
+	"base":      true,
+	"final":     true,
+	"interface": true,
+	"sealed":    true,
+	"mixin":     true,
+}
+
+// parseUnit parses the top-level declarations of the file
+func (p *fileParser) parseUnit() (*ast.CompilationUnit, error) {
+	unit := &ast.CompilationUnit{File: p.file.name}
+	states := make(map[string]*classDecl)
+	decls := make(map[*ast.WidgetClass]*classDecl)
+	var stateDecls []*classDecl // in source order
+
+	for p.peek().kind != tokenEOF {
+		switch {
+		case p.peek().is("@"):
+			if err := p.skipAnnotation(); err != nil {
+				return nil, err
+			}
+		case p.peek().is("import") && p.peekAt(1).kind == tokenString:
+			imp, err := p.parseImport()
+			if err != nil {
+				return nil, err
+			}
+			unit.Imports = append(unit.Imports, imp)
+		case p.atClassDeclaration():
+			decl, err := p.parseClass()
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case decl.abstract:
+				// Abstract classes are never built
+			case decl.superclass == "StatelessWidget" || decl.superclass == "StatefulWidget":
+				class := &ast.WidgetClass{
+					Name:       decl.name,
+					Kind:       ast.Stateless,
+					Fields:     decl.fields,
+					Parameters: decl.params,
+					Methods:    decl.methods,
+					Build:      decl.build,
+					Span:       decl.span,
+				}
+				if decl.superclass == "StatefulWidget" {
+					class.Kind = ast.Stateful
+				}
+				unit.Classes = append(unit.Classes, class)
+				decls[class] = decl
+			case decl.superclass == "State" && len(decl.superArgs) == 1:
+				states[decl.superArgs[0]] = decl
+				stateDecls = append(stateDecls, decl)
+			}
+		default:
+			start := p.pos
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+			if p.isMainFunction(start) {
+				entry, err := p.findRunAppArgument(start, p.pos)
+				if err != nil {
+					return nil, err
+				}
+				unit.Entry = entry
+			}
+		}
+	}
+
+	// A stateful widget is built by its State class, which must be
+	// declared in the same file
+	for _, class := range unit.Classes {
+		decl := decls[class]
+		if class.Kind == ast.Stateful {
+			state, ok := states[class.Name]
+			if !ok {
+				p.errorAt(decl.nameSpan, codeNoStateClass, "state class of %s not found: declare a class extending State<%s> in the same file", class.Name, class.Name)
+				continue
+			}
+			delete(states, class.Name)
+			class.Build = state.build
+			class.State = &ast.StateClass{
+				Name:    state.name,
+				Fields:  state.fields,
+				Methods: state.methods,
+				Span:    state.span,
+			}
+			decl = state
+		}
+		if class.Build == nil {
+			p.errorAt(decl.nameSpan, codeNoBuildMethod, "%s has no build method", decl.name)
+		}
+	}
+	for _, state := range stateDecls {
+		if widget := state.superArgs[0]; states[widget] == state {
+			p.errorAt(state.nameSpan, codeNoStateClass, "%s is the State of %s, which is not a StatefulWidget declared in the same file", state.name, widget)
+		}
+	}
+	return unit, nil
+}
+
+// parseImport parses an import directive, skipping any prefix and
+// combinators after the URI
+func (p *fileParser) parseImport() (*ast.Import, error) {
+	start := p.pos
+	p.advance()
+	uriTok := p.peek()
+	uri, err := p.parseStringLiteral()
+	if err != nil {
+		return nil, err
+	}
+	literal, ok := uri.(*ast.Literal)
+	if !ok {
+		return nil, p.errorf(uriTok, codeSyntax, "import URI cannot be interpolated")
+	}
+	if err := p.skipStatement(); err != nil {
+		return nil, err
+	}
+	return &ast.Import{URI: literal.Value, Span: p.spanFrom(start)}, nil
+}
+
+// atClassDeclaration reports whether a class declaration starts at the
+// current token, and if so skips any modifiers before the class keyword
+func (p *fileParser) atClassDeclaration() bool {
+	i := 0
+	for classModifiers[p.peekAt(i).text] && p.peekAt(i).kind == tokenIdent {
+		i++
+	}
+	if !p.peekAt(i).is("class") {
+		return false
+	}
+	p.pos += i
+	return true
+}
+
+// isMainFunction reports whether the declaration that starts at token
+// index start is the main function
+func (p *fileParser) isMainFunction(start int) bool {
+	for i := start; i < p.pos; i++ {
+		if p.tokens[i].is("(") || p.tokens[i].is("{") || p.tokens[i].is("=") || p.tokens[i].is(";") {
+			return false
+		}
+		if p.tokens[i].is("main") && p.tokens[i+1].is("(") {
+			return true
+		}
+	}
+	return false
+}
+
+// findRunAppArgument returns the widget passed to runApp between token
+// indexes start and end
+func (p *fileParser) findRunAppArgument(start, end int) (*ast.WidgetNode, error) {
+	save := p.pos
+	defer func() { p.pos = save }()
+	for i := start; i+1 < end; i++ {
+		if p.tokens[i].is("runApp") && p.tokens[i+1].is("(") {
+			p.pos = i + 2
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if call, ok := x.(*ast.Call); ok && call.Widget != nil {
+				return p.lowerWidget(call, true), nil
+			}
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// skipAnnotation skips an annotation such as @override or @Deprecated('x')
+func (p *fileParser) skipAnnotation() error {
+	p.advance()
+	for p.peek().kind == tokenIdent {
+		p.advance()
+		if !p.accept(".") {
+			break
+		}
+	}
+	if p.peek().is("(") {
+		return p.skipBalanced()
+	}
+	return nil
+}
+
+// parseClass parses a class declaration starting at the class keyword
+func (p *fileParser) parseClass() (*classDecl, error) {
+	start := p.pos
+	p.advance()
+	nameTok := p.peek()
+	if nameTok.kind != tokenIdent {
+		return nil, p.errorf(nameTok, codeSyntax, "expected class name but found %s", describe(nameTok))
+	}
+	p.advance()
+	decl := &classDecl{name: nameTok.text, nameSpan: p.file.span(nameTok.pos, nameTok.end)}
+	for i := start - 1; i >= 0 && classModifiers[p.tokens[i].text] && p.tokens[i].kind == tokenIdent; i-- {
+		decl.abstract = decl.abstract || p.tokens[i].is("abstract")
+	}
+	if p.peek().is("<") && !p.skipTypeArguments() {
+		return nil, p.errorf(p.peek(), codeSyntax, "malformed type parameters")
+	}
+
+	if p.accept("extends") {
+		superTok := p.peek()
+		if superTok.kind != tokenIdent {
+			return nil, p.errorf(superTok, codeSyntax, "expected superclass but found %s", describe(superTok))
+		}
+		p.advance()
+		decl.superclass = superTok.text
+		if p.accept("<") {
+			for {
+				arg, err := p.parseType()
+				if err != nil {
+					return nil, err
+				}
+				decl.superArgs = append(decl.superArgs, arg)
+				if !p.accept(",") {
+					break
+				}
+			}
+			if _, err := p.expect(">"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// with and implements clauses are not needed
+	for !p.peek().is("{") {
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf(p.peek(), codeSyntax, "expected class body")
+		}
+		p.advance()
+	}
+
+	p.advance()
+	for !p.accept("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf(p.peek(), codeSyntax, "unexpected end of file in class %s", decl.name)
+		}
+		if err := p.parseMember(decl); err != nil {
+			return nil, err
+		}
+	}
+	decl.span = p.spanFrom(start)
+	return decl, nil
+}
+
+// memberModifiers may precede a field, method or constructor
+var memberModifiers = map[string]bool{
+	"static":    true,
+	"final":     true,
+	"const":     true,
+	"late":      true,
+	"var":       true,
+	"external":  true,
+	"factory":   true,
+	"covariant": true,
+	"abstract":  true,
+}
+
+// parseMember parses a single class member, recording fields, methods,
+// the unnamed constructor's parameters and the build method
+func (p *fileParser) parseMember(decl *classDecl) error {
+	for p.peek().is("@") {
+		if err := p.skipAnnotation(); err != nil {
+			return err
+		}
+	}
+	if p.accept(";") {
+		return nil
+	}
+
+	start := p.pos
+	static, final, factory := false, false, false
+	for memberModifiers[p.peek().text] && p.peek().kind == tokenIdent && !p.peekAt(1).is("(") {
+		switch p.advance().text {
+		case "static":
+			static = true
+		case "final", "const":
+			final = true
+		case "factory":
+			factory = true
+		}
+	}
+
+	// Constructors: Name(...), Name.named(...)
+	if p.peek().is(decl.name) && (p.peekAt(1).is("(") || p.peekAt(1).is(".")) {
+		p.advance()
+		named := p.accept(".")
+		if named {
+			p.advance()
+		}
+		params, err := p.parseParameters()
+		if err != nil {
+			return err
+		}
+		if !named && !factory {
+			decl.params = params
+		}
+		return p.skipFunctionBody()
+	}
+
+	// Methods without a return type, e.g. initState() { ... }
+	if p.peek().kind == tokenIdent && p.peekAt(1).is("(") {
+		return p.parseMethod(decl, p.advance(), static)
+	}
+
+	typ := ""
+	if !(p.peek().kind == tokenIdent && isDeclarationEnd(p.peekAt(1))) {
+		var err error
+		if typ, err = p.parseType(); err != nil {
+			return err
+		}
+	}
+
+	// Getters, setters and operators
+	if (p.peek().is("get") || p.peek().is("set")) && p.peekAt(1).kind == tokenIdent {
+		p.advance()
+		p.advance()
+		if p.peek().is("(") {
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+		}
+		return p.skipFunctionBody()
+	}
+	if p.peek().is("operator") {
+		for !p.peek().is("(") && p.peek().kind != tokenEOF {
+			p.advance()
+		}
+		return p.skipMethod()
+	}
+
+	nameTok := p.peek()
+	if nameTok.kind != tokenIdent {
+		return p.errorf(nameTok, codeSyntax, "expected member name but found %s", describe(nameTok))
+	}
+	p.advance()
+
+	if p.peek().is("(") || p.peek().is("<") {
+		if nameTok.text == "build" && typ == "Widget" {
+			return p.parseBuildMethod(decl)
+		}
+		return p.parseMethod(decl, nameTok, static)
+	}
+
+	// One or more fields: Type a = x, b;
+	for {
+		field := &ast.Field{Name: nameTok.text, Type: typ, Final: final, Static: static}
+		if p.accept("=") {
+			value, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			field.Initializer = value
+		}
+		field.Span = p.spanFrom(start)
+		decl.fields = append(decl.fields, field)
+		if !p.accept(",") {
+			break
+		}
+		nameTok = p.peek()
+		if nameTok.kind != tokenIdent {
+			return p.errorf(nameTok, codeSyntax, "expected field name but found %s", describe(nameTok))
+		}
+		p.advance()
+	}
+	_, err := p.expect(";")
+	return err
+}
+
+func isDeclarationEnd(tok token) bool {
+	return tok.is("=") || tok.is(";") || tok.is(",")
+}
+
+// parseBuildMethod parses the parameters and body of a build method
+func (p *fileParser) parseBuildMethod(decl *classDecl) error {
+	if err := p.skipBalanced(); err != nil {
+		return err
+	}
+	build, err := p.parseBuildBody()
+	if err != nil {
+		return err
+	}
+	decl.build = build
+	return nil
+}
+
+// parseMethod parses the parameters and body of an instance method whose
+// name has been consumed. Static and abstract methods are skipped, as is
+// any method whose body uses syntax the parser does not model.
+func (p *fileParser) parseMethod(decl *classDecl, name token, static bool) error {
+	start := p.pos
+	if static {
+		return p.skipMethod()
+	}
+	if p.peek().is("<") && !p.skipTypeArguments() {
+		return p.errorf(p.peek(), codeSyntax, "malformed type parameters")
+	}
+	params, err := p.parseParameters()
+	if err != nil {
+		return err
+	}
+	if p.accept(";") {
+		return nil
+	}
+
+	bodyStart := p.pos
+	fn, err := p.parseFunctionBody(params)
+	if err != nil {
+		// Recover by skipping the body, so that a construct the parser
+		// does not understand only costs this method
+		p.pos = bodyStart
+		if skipErr := p.skipFunctionBody(); skipErr != nil {
+			return err
+		}
+		p.warnf(p.spanFrom(start), codeUnsupportedElement, "method %s was skipped: %s",
+			name.text, err.(diag.Diagnostic).Message)
+		return nil
+	}
+	if fn.Result != nil {
+		if _, err := p.expect(";"); err != nil {
+			return err
+		}
+	}
+	fn.Span = p.spanFrom(start)
+	decl.methods = append(decl.methods, &ast.Method{Name: name.text, Func: fn, Span: p.file.span(name.pos, name.end)})
+	return nil
+}
+
+// skipMethod skips a method's type parameters, parameters and body
+func (p *fileParser) skipMethod() error {
+	if p.peek().is("<") && !p.skipTypeArguments() {
+		return p.errorf(p.peek(), codeSyntax, "malformed type parameters")
+	}
+	if !p.peek().is("(") {
+		return p.errorf(p.peek(), codeSyntax, "expected parameter list but found %s", describe(p.peek()))
+	}
+	if err := p.skipBalanced(); err != nil {
+		return err
+	}
+	return p.skipFunctionBody()
+}
+
+// skipFunctionBody skips an optional initializer list and a block body,
+// arrow body or terminating semicolon
+func (p *fileParser) skipFunctionBody() error {
+	p.accept("async")
+	p.accept("sync")
+	p.accept("*")
+	if p.accept(":") {
+		// Initializer list or redirecting constructor
+		for !p.peek().is("{") && !p.peek().is(";") {
+			tok := p.peek()
+			switch {
+			case tok.kind == tokenEOF:
+				return p.errorf(tok, codeSyntax, "unexpected end of file in initializer list")
+			case tok.is("(") || tok.is("["):
+				if err := p.skipBalanced(); err != nil {
+					return err
+				}
+			default:
+				p.advance()
+			}
+		}
+	}
+	switch {
+	case p.peek().is("{"):
+		return p.skipBalanced()
+	case p.accept("=>"):
+		return p.skipStatement()
+	default:
+		_, err := p.expect(";")
+		return err
+	}
+}
+
+// parseParameters parses a constructor parameter list
+func (p *fileParser) parseParameters() ([]*ast.Parameter, error) {
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var params []*ast.Parameter
+	named, closer := false, ")"
+	for {
+		if p.accept(closer) {
+			if closer == ")" {
+				return params, nil
+			}
+			named, closer = false, ")"
+			continue
+		}
+		if closer == ")" && (p.peek().is("{") || p.peek().is("[")) {
+			named = p.advance().is("{")
+			closer = closerFor(p.tokens[p.pos-1].text)
+			continue
+		}
+		param, err := p.parseParameter(named)
+		if err != nil {
+			return nil, err
+		}
+		if param != nil {
+			params = append(params, param)
+		}
+		if !p.accept(",") && !p.peek().is(closer) {
+			return nil, p.errorf(p.peek(), codeSyntax, "expected \",\" or %q but found %s", closer, describe(p.peek()))
+		}
+	}
+}
+
+// parseParameter parses a single parameter. Super parameters such as
+// super.key are skipped and reported as nil.
+func (p *fileParser) parseParameter(named bool) (*ast.Parameter, error) {
+	for p.peek().is("@") {
+		if err := p.skipAnnotation(); err != nil {
+			return nil, err
+		}
+	}
+	start := p.pos
+	param := &ast.Parameter{Named: named}
+	param.Required = p.accept("required")
+	for p.peek().is("covariant") || p.peek().is("final") || p.peek().is("var") {
+		p.advance()
+	}
+
+	isSuper := false
+	switch {
+	case (p.peek().is("this") || p.peek().is("super")) && p.peekAt(1).is("."):
+		isSuper = p.advance().is("super")
+		p.advance()
+		param.Field = !isSuper
+	case p.peek().kind == tokenIdent && isParameterEnd(p.peekAt(1)):
+		// Untyped parameter
+	default:
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		param.Type = typ
+	}
+
+	nameTok := p.peek()
+	if nameTok.kind != tokenIdent {
+		return nil, p.errorf(nameTok, codeSyntax, "expected parameter name but found %s", describe(nameTok))
+	}
+	p.advance()
+	param.Name = nameTok.text
+	if p.peek().is("(") {
+		// Function-typed parameter, e.g. void onTap()
+		if err := p.skipBalanced(); err != nil {
+			return nil, err
+		}
+		p.accept("?")
+	}
+
+	if p.accept("=") || p.accept(":") {
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		param.Default = value
+	}
+	param.Span = p.spanFrom(start)
+	if isSuper {
+		return nil, nil
+	}
+	return param, nil
+}
+
+func isParameterEnd(tok token) bool {
+	return tok.is(",") || tok.is(")") || tok.is("}") || tok.is("]") || tok.is("=") || tok.is(":")
+}
+
+// parseType parses a type annotation and returns its source text
+func (p *fileParser) parseType() (string, error) {
+	start := p.pos
+	tok := p.peek()
+	if tok.kind != tokenIdent {
+		return "", p.errorf(tok, codeSyntax, "expected type but found %s", describe(tok))
+	}
+	p.advance()
+	for p.peek().is(".") && p.peekAt(1).kind == tokenIdent {
+		p.advance()
+		p.advance()
+	}
+	if p.peek().is("<") && !p.skipTypeArguments() {
+		return "", p.errorf(p.peek(), codeSyntax, "malformed type arguments")
+	}
+	if tok.is("Function") && p.peek().is("(") {
+		if err := p.skipBalanced(); err != nil {
+			return "", err
+		}
+	}
+	p.accept("?")
+	return p.sourceFrom(start), nil
+}
@@ -0,0 +1,524 @@
+package parser
+
+import (
+	"strings"
+
+	"compiler-go/pkg/ast"
+)
+
+// parseExpr parses a Dart expression, including assignments
+func (p *fileParser) parseExpr() (ast.Expr, error) {
+	start := p.pos
+	x, err := p.parseConditionalExpr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); isAssignmentOperator(tok) {
+		p.advance()
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Assign{Op: tok.text, Target: x, Value: value, Span: p.spanFrom(start)}, nil
+	}
+	// Cascades are kept as source text
+	if tok := p.peek(); tok.is("..") || tok.is("?..") {
+		for tok := p.peek(); isAssignmentOperator(tok) || tok.is("..") || tok.is("?.."); tok = p.peek() {
+			p.advance()
+			if _, err := p.parseConditionalExpr(); err != nil {
+				return nil, err
+			}
+		}
+		return &ast.RawExpr{Text: p.sourceFrom(start), Span: p.spanFrom(start)}, nil
+	}
+	return x, nil
+}
+
+func isAssignmentOperator(tok token) bool {
+	if tok.kind != tokenPunct {
+		return false
+	}
+	switch tok.text {
+	case "=", "+=", "-=", "*=", "/=", "~/=", "%=", "&=", "|=", "^=", "<<=", "??=":
+		return true
+	}
+	return false
+}
+
+// binaryOperators lists binary operators from lowest to highest precedence
+var binaryOperators = [][]string{
+	{"??"},
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", ">", "<=", ">=", "is", "as"},
+	{"|"},
+	{"^"},
+	{"&"},
+	{"<<", ">>"},
+	{"+", "-"},
+	{"*", "/", "%", "~/"},
+}
+
+// parseConditionalExpr parses `a ? b : c` and everything of higher
+// precedence
+func (p *fileParser) parseConditionalExpr() (ast.Expr, error) {
+	start := p.pos
+	cond, err := p.parseBinaryExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("?") {
+		return cond, nil
+	}
+	then, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &ast.Conditional{Cond: cond, Then: then, Else: els, Span: p.spanFrom(start)}, nil
+}
+
+func (p *fileParser) parseBinaryExpr(level int) (ast.Expr, error) {
+	if level == len(binaryOperators) {
+		return p.parseUnaryExpr()
+	}
+	start := p.pos
+	x, err := p.parseBinaryExpr(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.matchBinaryOperator(level)
+		if !ok {
+			return x, nil
+		}
+		var y ast.Expr
+		if op == "is" || op == "is!" || op == "as" {
+			typeStart := p.pos
+			typ, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			y = &ast.Ident{Name: typ, Span: p.spanFrom(typeStart)}
+		} else if y, err = p.parseBinaryExpr(level + 1); err != nil {
+			return nil, err
+		}
+		x = &ast.Binary{Op: op, X: x, Y: y, Span: p.spanFrom(start)}
+	}
+}
+
+// matchBinaryOperator consumes a binary operator of the given precedence
+// level, joining adjacent '>' tokens into a shift operator
+func (p *fileParser) matchBinaryOperator(level int) (string, bool) {
+	tok := p.peek()
+	for _, op := range binaryOperators[level] {
+		switch {
+		case op == ">>":
+			if next := p.peekAt(1); tok.is(">") && next.is(">") && next.pos == tok.end {
+				p.advance()
+				p.advance()
+				if p.peek().is(">") && p.peek().pos == next.end {
+					p.advance()
+					return ">>>", true
+				}
+				return ">>", true
+			}
+		case op == ">":
+			if next := p.peekAt(1); tok.is(">") && next.is(">") && next.pos == tok.end {
+				continue
+			}
+			if tok.is(">") {
+				p.advance()
+				return ">", true
+			}
+		case op == "is":
+			if tok.is("is") {
+				p.advance()
+				if p.accept("!") {
+					return "is!", true
+				}
+				return "is", true
+			}
+		case tok.is(op):
+			p.advance()
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *fileParser) parseUnaryExpr() (ast.Expr, error) {
+	start := p.pos
+	tok := p.peek()
+	if tok.is("-") || tok.is("!") || tok.is("~") || tok.is("++") || tok.is("--") || tok.is("await") {
+		p.advance()
+		x, err := p.parseUnaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Unary{Op: tok.text, X: x, Span: p.spanFrom(start)}, nil
+	}
+	return p.parsePostfixExpr()
+}
+
+// parsePostfixExpr parses a primary expression followed by selectors such
+// as member access, calls and indexing. A call with the shape of a
+// constructor call, like `Padding(...)` or `Image.network(...)`, is also
+// converted to a widget node.
+func (p *fileParser) parsePostfixExpr() (ast.Expr, error) {
+	p.accept("const")
+	p.accept("new")
+	start := p.pos
+
+	x, err := p.parsePrimaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.is(".") || tok.is("?."):
+			p.advance()
+			name := p.peek()
+			if name.kind != tokenIdent {
+				return nil, p.errorf(name, codeSyntax, "expected identifier after %q but found %s", tok.text, describe(name))
+			}
+			p.advance()
+			x = &ast.Member{X: x, Name: name.text, NullAware: tok.is("?."), Span: p.spanFrom(start)}
+		case tok.is("<") && p.isGenericCall():
+			// Type arguments of a generic call are not needed
+			p.skipTypeArguments()
+		case tok.is("("):
+			args, err := p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+			call := &ast.Call{Fun: x, Args: args, Span: p.spanFrom(start)}
+			if _, ok := constructorName(call.Fun); ok {
+				call.Widget = p.lowerWidget(call, false)
+			}
+			x = call
+		case tok.is("["):
+			p.advance()
+			index, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &ast.Index{X: x, Index: index, Span: p.spanFrom(start)}
+		case tok.is("!") || tok.is("++") || tok.is("--"):
+			p.advance()
+			x = &ast.Unary{Op: tok.text, X: x, Postfix: true, Span: p.spanFrom(start)}
+		default:
+			return x, nil
+		}
+	}
+}
+
+// parseArguments parses a parenthesized list of positional and named
+// arguments
+func (p *fileParser) parseArguments() ([]ast.Argument, error) {
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []ast.Argument
+	for !p.accept(")") {
+		var arg ast.Argument
+		if p.peek().kind == tokenIdent && p.peekAt(1).is(":") {
+			arg.Name = p.advance().text
+			p.advance()
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		arg.Value = value
+		args = append(args, arg)
+
+		if !p.accept(",") {
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return args, nil
+}
+
+// isGenericCall reports whether the '<' at the current position starts
+// type arguments of a call, as in `foo<int>(...)`
+func (p *fileParser) isGenericCall() bool {
+	save := p.pos
+	defer func() { p.pos = save }()
+	return p.skipTypeArguments() && p.peek().is("(")
+}
+
+// skipTypeArguments skips `<...>`, reporting false if the tokens do not
+// form a type argument list
+func (p *fileParser) skipTypeArguments() bool {
+	save := p.pos
+	if !p.accept("<") {
+		return false
+	}
+	depth := 1
+	for depth > 0 {
+		tok := p.advance()
+		switch {
+		case tok.is("<"):
+			depth++
+		case tok.is(">"):
+			depth--
+		case tok.kind == tokenIdent || tok.is(",") || tok.is(".") || tok.is("?") ||
+			tok.is("(") || tok.is(")"):
+		default:
+			p.pos = save
+			return false
+		}
+	}
+	return true
+}
+
+func (p *fileParser) parsePrimaryExpr() (ast.Expr, error) {
+	start := p.pos
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenString:
+		return p.parseStringLiteral()
+	case tok.kind == tokenNumber:
+		p.advance()
+		return &ast.Literal{Kind: ast.NumberLiteral, Value: tok.text, Span: p.spanFrom(start)}, nil
+	case tok.is("true") || tok.is("false"):
+		p.advance()
+		return &ast.Literal{Kind: ast.BoolLiteral, Value: tok.text, Span: p.spanFrom(start)}, nil
+	case tok.is("null"):
+		p.advance()
+		return &ast.Literal{Kind: ast.NullLiteral, Value: tok.text, Span: p.spanFrom(start)}, nil
+	case tok.is("throw"):
+		// Throw expressions are kept as source text
+		p.advance()
+		if _, err := p.parseExpr(); err != nil {
+			return nil, err
+		}
+		return &ast.RawExpr{Text: p.sourceFrom(start), Span: p.spanFrom(start)}, nil
+	case tok.kind == tokenIdent:
+		p.advance()
+		return &ast.Ident{Name: tok.text, Span: p.spanFrom(start)}, nil
+	case tok.is("<"):
+		// Typed collection literal, e.g. <Widget>[...]
+		if !p.skipTypeArguments() || !(p.peek().is("[") || p.peek().is("{")) {
+			return nil, p.errorf(tok, codeSyntax, "unexpected %s", describe(tok))
+		}
+		return p.parsePrimaryExpr()
+	case tok.is("["):
+		return p.parseListLiteral()
+	case tok.is("{"):
+		return p.parseMapLiteral()
+	case tok.is("("):
+		if p.isFunctionLiteral() {
+			fn, err := p.parseFunctionLiteral()
+			if err != nil {
+				// Keep a function literal that uses syntax the parser does
+				// not model as source text, so the generator can report it
+				p.pos = start
+				if err := p.skipFunctionLiteral(); err != nil {
+					return nil, err
+				}
+				return &ast.RawExpr{Text: p.sourceFrom(start), Span: p.spanFrom(start)}, nil
+			}
+			return fn, nil
+		}
+		p.advance()
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &ast.Paren{X: x, Span: p.spanFrom(start)}, nil
+	}
+	return nil, p.errorf(tok, codeSyntax, "unexpected %s", describe(tok))
+}
+
+// parseListLiteral parses `[a, b, ...]`. Collection if and for elements
+// and spreads are kept as source text.
+func (p *fileParser) parseListLiteral() (*ast.ListLit, error) {
+	start := p.pos
+	if _, err := p.expect("["); err != nil {
+		return nil, err
+	}
+	list := &ast.ListLit{}
+	for !p.accept("]") {
+		element, err := p.parseCollectionElement()
+		if err != nil {
+			return nil, err
+		}
+		list.Elements = append(list.Elements, element)
+		if !p.accept(",") {
+			if _, err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	list.Span = p.spanFrom(start)
+	return list, nil
+}
+
+// parseMapLiteral parses a map literal `{k: v, ...}` or a set literal
+// `{a, b, ...}`
+func (p *fileParser) parseMapLiteral() (*ast.MapLit, error) {
+	start := p.pos
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	m := &ast.MapLit{}
+	for !p.accept("}") {
+		element, err := p.parseCollectionElement()
+		if err != nil {
+			return nil, err
+		}
+		entry := ast.MapEntry{Value: element}
+		if _, raw := element.(*ast.RawExpr); !raw && p.accept(":") {
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			entry = ast.MapEntry{Key: element, Value: value}
+		}
+		m.Entries = append(m.Entries, entry)
+		if !p.accept(",") {
+			if _, err := p.expect("}"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	m.Span = p.spanFrom(start)
+	return m, nil
+}
+
+// parseCollectionElement parses an element of a list, set or map literal
+func (p *fileParser) parseCollectionElement() (ast.Expr, error) {
+	start := p.pos
+	tok := p.peek()
+	if !(tok.is("if") || tok.is("for") || tok.is("...") || tok.is("...?")) {
+		return p.parseExpr()
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, p.errorf(tok, codeSyntax, "unexpected end of file in collection literal")
+		case tok.is(",") || tok.is("]") || tok.is("}"):
+			return &ast.RawExpr{Text: p.sourceFrom(start), Span: p.spanFrom(start)}, nil
+		case tok.is("(") || tok.is("[") || tok.is("{"):
+			if err := p.skipBalanced(); err != nil {
+				return nil, err
+			}
+		default:
+			p.advance()
+		}
+	}
+}
+
+// isFunctionLiteral reports whether a function literal such as
+// `() { ... }`, `(a, b) => ...` or `() async { ... }` starts here
+func (p *fileParser) isFunctionLiteral() bool {
+	if !p.peek().is("(") {
+		return false
+	}
+	save := p.pos
+	defer func() { p.pos = save }()
+	if err := p.skipBalanced(); err != nil {
+		return false
+	}
+	p.accept("async")
+	p.accept("sync")
+	p.accept("*")
+	return p.peek().is("{") || p.peek().is("=>")
+}
+
+// parseFunctionLiteral parses a function literal with a block or arrow
+// body
+func (p *fileParser) parseFunctionLiteral() (*ast.FuncLit, error) {
+	start := p.pos
+	params, err := p.parseParameters()
+	if err != nil {
+		return nil, err
+	}
+	fn, err := p.parseFunctionBody(params)
+	if err != nil {
+		return nil, err
+	}
+	fn.Span = p.spanFrom(start)
+	return fn, nil
+}
+
+// parseFunctionBody parses the block or arrow body of a function literal
+// or method whose parameters have been parsed
+func (p *fileParser) parseFunctionBody(params []*ast.Parameter) (*ast.FuncLit, error) {
+	fn := &ast.FuncLit{Params: params}
+	fn.Async = p.accept("async")
+	p.accept("sync")
+	p.accept("*")
+	if p.accept("=>") {
+		result, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		fn.Result = result
+		return fn, nil
+	}
+	body, err := p.parseBlock()
+	if err != nil {
+		return nil, err
+	}
+	fn.Body = body
+	return fn, nil
+}
+
+// skipFunctionLiteral skips a function literal with a block or arrow body
+// without parsing the body
+func (p *fileParser) skipFunctionLiteral() error {
+	if err := p.skipBalanced(); err != nil {
+		return err
+	}
+	p.accept("async")
+	p.accept("sync")
+	p.accept("*")
+	if !p.accept("=>") {
+		return p.skipBalanced()
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(tok, codeSyntax, "unexpected end of file in function literal")
+		case tok.is(",") || tok.is(";") || tok.is(")") || tok.is("]") || tok.is("}"):
+			return nil
+		case tok.is("(") || tok.is("[") || tok.is("{"):
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+		default:
+			p.advance()
+		}
+	}
+}
+
+// isUpper reports whether name starts with an upper-case letter, ignoring
+// the leading underscores of library-private names such as _Header
+func isUpper(name string) bool {
+	name = strings.TrimLeft(name, "_")
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
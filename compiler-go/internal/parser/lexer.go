@@ -0,0 +1,264 @@
+package parser
+
+import (
+	"strings"
+)
+
+// tokenKind identifies the lexical class of a token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenPunct
+)
+
+// token is a single lexical element of Dart source
+type token struct {
+	kind tokenKind
+	text string // exact source text, including quotes for strings
+	pos  int    // byte offset of the first character
+	end  int    // byte offset just past the last character
+}
+
+func (t token) is(text string) bool {
+	return (t.kind == tokenPunct || t.kind == tokenIdent) && t.text == text
+}
+
+// punctuators lists multi-character operators, longest first. Shift
+// operators are deliberately absent so that nested type arguments such as
+// List<List<int>> lex as separate '>' tokens.
+var punctuators = []string{
+	"...?", "??=", "~/=", "<<=", "?..",
+	"...", "==", "!=", "<=", ">=", "&&", "||", "=>", "++", "--",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "??", "?.",
+	"<<", "~/", "..",
+}
+
+// lexer splits Dart source into tokens, skipping whitespace and comments
+type lexer struct {
+	file *sourceFile
+	src  string
+	pos  int
+}
+
+// tokenize returns every token in the file followed by a tokenEOF token
+func tokenize(file *sourceFile) ([]token, error) {
+	l := &lexer{file: file, src: file.text}
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+// next scans the next token
+func (l *lexer) next() (token, error) {
+	if err := l.skipTrivia(); err != nil {
+		return token{}, err
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, pos: start, end: start}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case (c == 'r' || c == 'R') && l.pos+1 < len(l.src) && isQuote(l.src[l.pos+1]):
+		l.pos++
+		if err := l.scanString(true); err != nil {
+			return token{}, err
+		}
+		return l.emit(tokenString, start), nil
+	case isQuote(c):
+		if err := l.scanString(false); err != nil {
+			return token{}, err
+		}
+		return l.emit(tokenString, start), nil
+	case isIdentStart(c):
+		for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+			l.pos++
+		}
+		return l.emit(tokenIdent, start), nil
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		l.scanNumber()
+		return l.emit(tokenNumber, start), nil
+	}
+
+	for _, punct := range punctuators {
+		if strings.HasPrefix(l.src[l.pos:], punct) {
+			l.pos += len(punct)
+			return l.emit(tokenPunct, start), nil
+		}
+	}
+	if strings.ContainsRune("(){}[];,.:?=<>+-*/%!~&|^@#", rune(c)) {
+		l.pos++
+		return l.emit(tokenPunct, start), nil
+	}
+	return token{}, l.file.errorf(start, start+1, codeUnexpectedChar, "unexpected character %q", c)
+}
+
+func (l *lexer) emit(kind tokenKind, start int) token {
+	return token{kind: kind, text: l.src[start:l.pos], pos: start, end: l.pos}
+}
+
+// skipTrivia skips whitespace, line comments and (nested) block comments
+func (l *lexer) skipTrivia() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			start := l.pos
+			depth := 0
+			for {
+				if l.pos >= len(l.src) {
+					return l.file.errorf(start, start+2, codeUnterminated, "unterminated block comment")
+				}
+				if strings.HasPrefix(l.src[l.pos:], "/*") {
+					depth++
+					l.pos += 2
+				} else if strings.HasPrefix(l.src[l.pos:], "*/") {
+					depth--
+					l.pos += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					l.pos++
+				}
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// scanString scans a quoted string literal starting at the opening quote.
+// Interpolated expressions (${...}) are scanned as tokens so that quotes
+// and braces inside them do not end the literal early.
+func (l *lexer) scanString(raw bool) error {
+	start := l.pos
+	quote := l.src[l.pos : l.pos+1]
+	if strings.HasPrefix(l.src[l.pos:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	l.pos += len(quote)
+	multiline := len(quote) == 3
+
+	for {
+		if l.pos >= len(l.src) {
+			return l.file.errorf(start, start+len(quote), codeUnterminated, "unterminated string literal")
+		}
+		if strings.HasPrefix(l.src[l.pos:], quote) {
+			l.pos += len(quote)
+			return nil
+		}
+		c := l.src[l.pos]
+		switch {
+		case c == '\n' && !multiline:
+			return l.file.errorf(start, l.pos, codeUnterminated, "unterminated string literal")
+		case c == '\\' && !raw:
+			l.pos += 2
+		case c == '$' && !raw && strings.HasPrefix(l.src[l.pos:], "${"):
+			l.pos += 2
+			if err := l.scanInterpolation(); err != nil {
+				return err
+			}
+		default:
+			l.pos++
+		}
+	}
+}
+
+// scanInterpolation consumes tokens up to and including the '}' that
+// closes an interpolated expression
+func (l *lexer) scanInterpolation() error {
+	start := l.pos
+	depth := 1
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tok.kind == tokenEOF:
+			return l.file.errorf(start-2, start, codeUnterminated, "unterminated string interpolation")
+		case tok.is("{"):
+			depth++
+		case tok.is("}"):
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// scanNumber scans decimal, exponent and hexadecimal number literals
+func (l *lexer) scanNumber() {
+	if strings.HasPrefix(l.src[l.pos:], "0x") || strings.HasPrefix(l.src[l.pos:], "0X") {
+		l.pos += 2
+		for l.pos < len(l.src) && isHexDigit(l.src[l.pos]) {
+			l.pos++
+		}
+		return
+	}
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos+1 < len(l.src) && l.src[l.pos] == '.' && isDigit(l.src[l.pos+1]) {
+		l.pos++
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		save := l.pos
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+			l.pos = save
+			return
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+}
+
+func isQuote(c byte) bool {
+	return c == '\'' || c == '"'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"compiler-go/internal/diag"
+)
+
+// lex returns the kinds and texts of the tokens of src, without the
+// tokenEOF token, as kind:text strings
+func lex(t *testing.T, src string) []string {
+	t.Helper()
+	tokens, err := tokenize(newSourceFile("test.dart", src))
+	if err != nil {
+		t.Fatalf("tokenize(%q): %v", src, err)
+	}
+	kinds := map[tokenKind]string{tokenIdent: "ident", tokenNumber: "number", tokenString: "string", tokenPunct: "punct"}
+	var got []string
+	for _, tok := range tokens[:len(tokens)-1] {
+		got = append(got, kinds[tok.kind]+":"+tok.text)
+	}
+	return got
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{"foo _bar $baz größe", []string{"ident:foo", "ident:_bar", "ident:$baz", "ident:größe"}},
+		{"1 2.5 .5 1e3 1.5E-3 0xFF 1.toString 1e", []string{
+			"number:1", "number:2.5", "number:.5", "number:1e3", "number:1.5E-3", "number:0xFF",
+			"number:1", "punct:.", "ident:toString", "number:1", "ident:e",
+		}},
+		{"a ?.. b ?. c ... d ...? e .. f", []string{
+			"ident:a", "punct:?..", "ident:b", "punct:?.", "ident:c", "punct:...", "ident:d",
+			"punct:...?", "ident:e", "punct:..", "ident:f",
+		}},
+		{"a ~/= b ??= c ~/ d << e", []string{
+			"ident:a", "punct:~/=", "ident:b", "punct:??=", "ident:c", "punct:~/", "ident:d", "punct:<<", "ident:e",
+		}},
+		// Closing type arguments are separate tokens
+		{"List<List<int>>", []string{"ident:List", "punct:<", "ident:List", "punct:<", "ident:int", "punct:>", "punct:>"}},
+		{"a // line\n/* block /* nested */ still */ b /// doc\nc", []string{"ident:a", "ident:b", "ident:c"}},
+		{`'a' "b" r'c\d' R"e"`, []string{`string:'a'`, `string:"b"`, `string:r'c\d'`, `string:R"e"`}},
+		{`'it\'s' "say \"hi\""`, []string{`string:'it\'s'`, `string:"say \"hi\""`}},
+		{"'''a\n'b'\n''' \"\"\"c\"\"\"", []string{"string:'''a\n'b'\n'''", `string:"""c"""`}},
+		// Quotes and braces inside an interpolation do not end the string
+		{`'a ${b['c']} ${ {'d': 1}['d'] } e' f`, []string{`string:'a ${b['c']} ${ {'d': 1}['d'] } e'`, "ident:f"}},
+		{`'$a' r'${b'`, []string{`string:'$a'`, `string:r'${b'`}},
+	}
+	for _, tt := range tests {
+		got := lex(t, tt.src)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("tokenize(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizePositions(t *testing.T) {
+	src := "a\n  'b' c"
+	tokens, err := tokenize(newSourceFile("test.dart", src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ pos, end int }{{0, 1}, {4, 7}, {8, 9}, {9, 9}}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, w := range want {
+		if tokens[i].pos != w.pos || tokens[i].end != w.end {
+			t.Errorf("token %d %q spans [%d, %d), want [%d, %d)", i, tokens[i].text, tokens[i].pos, tokens[i].end, w.pos, w.end)
+		}
+	}
+	if tokens[3].kind != tokenEOF {
+		t.Errorf("last token is %q, want end of file", tokens[3].text)
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		code string
+		span string // line:column-line:column
+	}{
+		{"a `b`", codeUnexpectedChar, "1:3-1:4"},
+		{"a\n'abc", codeUnterminated, "2:1-2:2"},
+		{"'abc\nd'", codeUnterminated, "1:1-1:5"},
+		{"x = '''abc\n", codeUnterminated, "1:5-1:8"},
+		{"'a ${b", codeUnterminated, "1:4-1:6"},
+		{"/* a /* b */", codeUnterminated, "1:1-1:3"},
+	}
+	for _, tt := range tests {
+		_, err := tokenize(newSourceFile("test.dart", tt.src))
+		d, ok := err.(diag.Diagnostic)
+		if !ok {
+			t.Errorf("tokenize(%q) = %v, want a diagnostic", tt.src, err)
+			continue
+		}
+		if d.Code != tt.code {
+			t.Errorf("tokenize(%q) reported %s %q, want %s", tt.src, d.Code, d.Message, tt.code)
+		}
+		if tt.span != "" {
+			if got := spanString(d.Span.Start.Line, d.Span.Start.Column, d.Span.End.Line, d.Span.End.Column); got != tt.span {
+				t.Errorf("tokenize(%q) reported %s at %s, want %s", tt.src, d.Code, got, tt.span)
+			}
+		}
+	}
+}
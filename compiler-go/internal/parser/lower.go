@@ -0,0 +1,270 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"compiler-go/pkg/ast"
+)
+
+// lowerWidget converts a constructor call to a widget node. Named
+// arguments become properties, except for a list of children; positional
+// arguments are kept in order. When warn is set, arguments that cannot be
+// represented are reported as they are dropped.
+func (p *fileParser) lowerWidget(call *ast.Call, warn bool) *ast.WidgetNode {
+	if !warn && call.Widget != nil {
+		return call.Widget
+	}
+	name, _ := constructorName(call.Fun)
+	widget := &ast.WidgetNode{
+		Name:       name, // e.g., Image.network -> Image_network
+		Properties: make(map[string]ast.Value),
+		Children:   make([]*ast.WidgetNode, 0),
+		Span:       call.Span,
+	}
+	for _, arg := range call.Args {
+		value := p.lowerValue(arg.Value, warn)
+		list, isList := value.(*ast.ListValue)
+		switch {
+		case arg.Name == "":
+			widget.Arguments = append(widget.Arguments, value)
+		case arg.Name == "children" && isList:
+			// Special handling for children: [...]
+			for _, item := range list.Elements {
+				if child, ok := item.(*ast.WidgetNode); ok {
+					widget.Children = append(widget.Children, child)
+				}
+			}
+		case isList:
+			// For other list properties, not 'children'
+			if warn {
+				p.warnf(list.Span, codeUnsupportedElement, "list property %q is not supported and was skipped", arg.Name)
+			}
+		default:
+			if _, ok := widget.Properties[arg.Name]; !ok {
+				widget.Order = append(widget.Order, arg.Name)
+			}
+			widget.Properties[arg.Name] = value
+		}
+	}
+	return widget
+}
+
+// lowerValue converts an expression used as a widget argument to a value.
+// Widget constructor calls, literals, collections, static references and
+// variable references become structured values, and callbacks and
+// expressions that read variables are kept for the generator to compile.
+// Anything else is kept as its source text.
+func (p *fileParser) lowerValue(e ast.Expr, warn bool) ast.Value {
+	span := e.NodeSpan()
+	switch x := e.(type) {
+	case *ast.Call:
+		if _, ok := constructorName(x.Fun); ok {
+			return p.lowerWidget(x, warn)
+		}
+	case *ast.Literal:
+		switch x.Kind {
+		case ast.StringLiteral:
+			return &ast.StringValue{Value: x.Value, Span: span}
+		case ast.NumberLiteral:
+			if number, ok := parseNumber(x.Value); ok {
+				return &ast.NumberValue{Value: number, Span: span}
+			}
+		case ast.BoolLiteral:
+			return &ast.BoolValue{Value: x.Value == "true", Span: span}
+		case ast.NullLiteral:
+			return &ast.NullValue{Span: span}
+		}
+	case *ast.Unary:
+		// Negative numbers are parsed as unary minus
+		if literal, ok := x.X.(*ast.Literal); ok && x.Op == "-" && !x.Postfix && literal.Kind == ast.NumberLiteral {
+			if number, ok := parseNumber(literal.Value); ok {
+				return &ast.NumberValue{Value: -number, Span: span}
+			}
+		}
+	case *ast.ListLit:
+		list := &ast.ListValue{Elements: make([]ast.Value, 0), Span: span}
+		for _, element := range x.Elements {
+			switch element := element.(type) {
+			case *ast.RawExpr:
+				// Collection if/for and spreads have no static representation
+				if warn {
+					p.warnf(element.Span, codeUnsupportedElement, "collection %q element is not supported and was skipped",
+						strings.Fields(element.Text)[0])
+				}
+			default:
+				list.Elements = append(list.Elements, p.lowerValue(element, warn))
+			}
+		}
+		return list
+	case *ast.MapLit:
+		if m, ok := p.lowerMap(x, warn); ok {
+			return m
+		}
+	case *ast.FuncLit:
+		return x
+	case *ast.StringInterpolation:
+		return x
+	}
+
+	if name, ok := staticReference(e); ok {
+		return &ast.EnumRef{Name: name, Span: span}
+	}
+	if name, ok := variableReference(e); ok {
+		return &ast.IdentRef{Name: name, Span: span}
+	}
+	if !hasStaticCall(e) {
+		return &ast.ExprValue{X: e, Span: span}
+	}
+	return &ast.RawValue{Text: p.src[span.Start.Offset:span.End.Offset], Span: span}
+}
+
+// lowerMap converts a map literal whose entries are all key: value pairs.
+// Set literals and maps with collection if, for or spread entries are not
+// converted.
+func (p *fileParser) lowerMap(x *ast.MapLit, warn bool) (*ast.MapValue, bool) {
+	m := &ast.MapValue{Span: x.Span}
+	for _, entry := range x.Entries {
+		if entry.Key == nil {
+			return nil, false
+		}
+		m.Entries = append(m.Entries, ast.MapValueEntry{
+			Key:   p.lowerValue(entry.Key, warn),
+			Value: p.lowerValue(entry.Value, warn),
+		})
+	}
+	return m, true
+}
+
+// constructorName returns the widget name for the callee of a call that
+// has the shape of a constructor call: an upper-case name optionally
+// followed by a named constructor, as in Text or Image.network
+func constructorName(fun ast.Expr) (string, bool) {
+	var parts []string
+	for {
+		switch x := fun.(type) {
+		case *ast.Member:
+			if x.NullAware {
+				return "", false
+			}
+			parts = append([]string{x.Name}, parts...)
+			fun = x.X
+			continue
+		case *ast.Ident:
+			if !isUpper(x.Name) {
+				return "", false
+			}
+			parts = append([]string{x.Name}, parts...)
+			return strings.Join(parts, "_"), true
+		}
+		return "", false
+	}
+}
+
+// parseNumber converts the text of a decimal or hexadecimal number
+// literal
+func parseNumber(text string) (float64, bool) {
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		n, err := strconv.ParseUint(text[2:], 16, 64)
+		return float64(n), err == nil
+	}
+	n, err := strconv.ParseFloat(text, 64)
+	return n, err == nil
+}
+
+// staticReference returns the source form of a reference to an enum value
+// or static member, such as MainAxisAlignment.center or Colors.grey[300]
+func staticReference(e ast.Expr) (string, bool) {
+	switch x := e.(type) {
+	case *ast.Member:
+		if x.NullAware {
+			return "", false
+		}
+		if base, ok := x.X.(*ast.Ident); ok && isUpper(base.Name) {
+			return base.Name + "." + x.Name, true
+		}
+		if base, ok := staticReference(x.X); ok {
+			return base + "." + x.Name, true
+		}
+	case *ast.Index:
+		literal, ok := x.Index.(*ast.Literal)
+		if base, isStatic := staticReference(x.X); isStatic && ok && literal.Kind == ast.NumberLiteral {
+			return base + "[" + literal.Value + "]", true
+		}
+	}
+	return "", false
+}
+
+// variableReference returns the source form of a variable reference such
+// as `title`, `this.title` or `widget.title`. Chains that start with an
+// upper-case name, like MainAxisAlignment.center, are not variable
+// references.
+func variableReference(e ast.Expr) (string, bool) {
+	switch x := e.(type) {
+	case *ast.Ident:
+		if isUpper(x.Name) || isReservedWord(x.Name) {
+			return "", false
+		}
+		return x.Name, true
+	case *ast.Member:
+		if x.NullAware {
+			return "", false
+		}
+		if this, ok := x.X.(*ast.Ident); ok && this.Name == "this" {
+			return "this." + x.Name, true
+		}
+		if base, ok := variableReference(x.X); ok {
+			return base + "." + x.Name, true
+		}
+	}
+	return "", false
+}
+
+// hasStaticCall reports whether an expression calls a static method such
+// as Theme.of(context), which has no runtime equivalent
+func hasStaticCall(e ast.Expr) bool {
+	found := false
+	inspectExpr(e, func(e ast.Expr) bool {
+		if call, ok := e.(*ast.Call); ok && call.Widget != nil {
+			if _, member := call.Fun.(*ast.Member); member {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// inspectExpr calls f for e and each of its subexpressions until f returns
+// false. Function literal bodies and the type operands of is, is! and as
+// are not visited.
+func inspectExpr(e ast.Expr, f func(ast.Expr) bool) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case nil:
+			return false
+		case *ast.FuncLit:
+			f(x)
+			return false
+		case *ast.Binary:
+			if x.Op == "is" || x.Op == "is!" || x.Op == "as" {
+				if f(x) {
+					inspectExpr(x.X, f)
+				}
+				return false
+			}
+		}
+		return f(n.(ast.Expr))
+	})
+}
+
+// isReservedWord reports whether name is a Dart keyword that cannot be a
+// variable reference
+func isReservedWord(name string) bool {
+	switch name {
+	case "true", "false", "null", "this", "super", "if", "for", "else", "return",
+		"const", "new", "var", "final", "is", "as", "in", "switch", "case":
+		return true
+	}
+	return false
+}
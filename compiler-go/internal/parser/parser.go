@@ -1,11 +1,8 @@
 package parser
 
 import (
-	"fmt"
-	"regexp"
-	"strings"
-
-	"compiler-go/internal/ast"
+	"compiler-go/internal/diag"
+	"compiler-go/pkg/ast"
 )
 
 // Parser represents a Dart file parser
@@ -20,299 +17,269 @@ func NewParser() *Parser {
 
 // Parse parses Dart code and returns a widget tree
 func (p *Parser) Parse(content string) (*ast.WidgetTree, error) {
-	// Find the build method
-	buildMethod := p.findBuildMethod(content)
-	if buildMethod == "" {
-		return nil, fmt.Errorf("build method not found")
+	tree, diags := p.ParseFile("", content)
+	if err := diags.Err(); err != nil {
+		return nil, err
 	}
+	return tree, nil
+}
 
-	// Extract the widget tree from the build method
-	widgetTree, err := p.extractWidgetTree(buildMethod)
+// ParseFile parses the Dart file named filename and returns the widget
+// tree of its first build method along with any diagnostics. The tree is
+// nil if the diagnostics contain an error.
+func (p *Parser) ParseFile(filename, content string) (*ast.WidgetTree, diag.List) {
+	unit, diags := p.ParseUnit(filename, content)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	for _, class := range unit.Classes {
+		if class.Build != nil {
+			return class.Build, diags
+		}
+	}
+	return nil, append(diags, diag.Errorf(ast.Span{File: filename}, codeNoBuildMethod, "build method not found"))
+}
+
+// ParseUnit parses the Dart file named filename and returns every widget
+// class it declares along with any diagnostics. The unit is nil if the
+// diagnostics contain an error.
+func (p *Parser) ParseUnit(filename, content string) (*ast.CompilationUnit, diag.List) {
+	file := newSourceFile(filename, content)
+	tokens, err := tokenize(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract widget tree: %v", err)
+		return nil, diag.List{err.(diag.Diagnostic)}
 	}
+	fp := &fileParser{file: file, src: content, tokens: tokens}
 
-	return widgetTree, nil
+	unit, err := fp.parseUnit()
+	if err != nil {
+		return nil, append(fp.diags, err.(diag.Diagnostic))
+	}
+	if fp.diags.HasErrors() {
+		return nil, fp.diags
+	}
+	return unit, fp.diags
 }
 
-// findBuildMethod finds the build method in the Dart code
-func (p *Parser) findBuildMethod(content string) string {
-	// Look for the build method (multi-line)
-	buildRegex := regexp.MustCompile(`Widget\s+build\s*\(\s*BuildContext\s+context\s*\)\s*{([\s\S]*?)\n}`)
-	matches := buildRegex.FindStringSubmatch(content)
-	if len(matches) < 2 {
-		return ""
-	}
-	fmt.Println("=== buildMethod ===")
-	fmt.Println(matches[1])
-	return matches[1]
+// fileParser holds the state for parsing a single file. A new one is
+// created for every call to Parse so a Parser can be shared.
+type fileParser struct {
+	file   *sourceFile
+	src    string
+	tokens []token
+	pos    int
+	diags  diag.List
 }
 
-// extractWidgetTree extracts the widget tree from the build method
-func (p *Parser) extractWidgetTree(buildMethod string) (*ast.WidgetTree, error) {
-	// Find the return statement (multi-line)
-	returnRegex := regexp.MustCompile(`return\s+([\s\S]+?);`)
-	matches := returnRegex.FindStringSubmatch(buildMethod)
-	if len(matches) < 2 {
-		return nil, fmt.Errorf("return statement not found")
+// errorf returns an error diagnostic spanning tok
+func (p *fileParser) errorf(tok token, code, format string, args ...interface{}) error {
+	end := tok.end
+	if end == tok.pos {
+		end++
 	}
-	fmt.Println("=== returnExpr ===")
-	fmt.Println(matches[1])
+	return p.file.errorf(tok.pos, end, code, format, args...)
+}
 
-	// Parse the widget tree
-	widgetNode := p.parseWidgetExpression(matches[1])
-	if widgetNode == nil {
-		return nil, fmt.Errorf("failed to parse widget expression")
-	}
+// warnf records a warning spanning span
+func (p *fileParser) warnf(span ast.Span, code, format string, args ...interface{}) {
+	p.diags = append(p.diags, diag.Warningf(span, code, format, args...))
+}
 
-	return &ast.WidgetTree{Root: widgetNode}, nil
+// errorAt records an error spanning span that does not stop parsing
+func (p *fileParser) errorAt(span ast.Span, code, format string, args ...interface{}) {
+	p.diags = append(p.diags, diag.Errorf(span, code, format, args...))
 }
 
-// parseWidgetExpression parses a widget expression with robust parenthesis matching
-func (p *Parser) parseWidgetExpression(expr string) *ast.WidgetNode {
-	expr = strings.TrimSpace(expr)
-	if expr == "" {
-		return nil
+// spanFrom returns the span from token index start up to the last
+// consumed token
+func (p *fileParser) spanFrom(start int) ast.Span {
+	if p.pos <= start {
+		tok := p.tokens[start]
+		return p.file.span(tok.pos, tok.end)
 	}
+	return p.file.span(p.tokens[start].pos, p.tokens[p.pos-1].end)
+}
 
-	// Ignore 'const' keyword
-	if strings.HasPrefix(expr, "const ") {
-		expr = strings.TrimSpace(expr[5:])
-	}
+func (p *fileParser) peek() token {
+	return p.tokens[p.pos]
+}
 
-	// Support chained constructors: Widget.namedConstructor(...)
-	parenIdx := strings.Index(expr, "(")
-	if parenIdx == -1 {
-		return nil
+func (p *fileParser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
 	}
-	widgetName := strings.TrimSpace(expr[:parenIdx])
-	widgetName = strings.ReplaceAll(widgetName, ".", "_") // e.g., Image.network -> Image_network
+	return p.tokens[p.pos+offset]
+}
 
-	// Find the matching closing parenthesis for the first '('
-	argsStart := parenIdx + 1
-	parenDepth := 1
-	argsEnd := -1
-	for i := argsStart; i < len(expr); i++ {
-		c := expr[i]
-		if c == '(' {
-			parenDepth++
-		} else if c == ')' {
-			parenDepth--
-			if parenDepth == 0 {
-				argsEnd = i
-				break
-			}
-		}
+func (p *fileParser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
 	}
-	if argsEnd == -1 {
-		return nil // Unmatched parenthesis
+	return tok
+}
+
+// accept consumes the current token if it matches text
+func (p *fileParser) accept(text string) bool {
+	if p.peek().is(text) {
+		p.advance()
+		return true
 	}
-	args := expr[argsStart:argsEnd]
+	return false
+}
 
-	widget := &ast.WidgetNode{
-		Name:       widgetName,
-		Properties: make(map[string]ast.PropertyValue),
-		Children:   make([]*ast.WidgetNode, 0),
+// expect consumes the current token, failing if it does not match text
+func (p *fileParser) expect(text string) (token, error) {
+	tok := p.peek()
+	if !tok.is(text) {
+		return tok, p.errorf(tok, codeSyntax, "expected %q but found %s", text, describe(tok))
 	}
+	return p.advance(), nil
+}
 
-	// Split arguments at top level only
-	argsList := splitArgsTopLevel(args)
-	for _, arg := range argsList {
-		arg = strings.TrimSpace(arg)
-		if arg == "" {
-			continue
-		}
-		// Parse key: value
-		kv := strings.SplitN(arg, ":", 2)
-		if len(kv) != 2 {
-			continue
-		}
-		propName := strings.TrimSpace(kv[0])
-		propValue := strings.TrimSpace(kv[1])
+// sourceFrom returns the source text from token index start up to the
+// last consumed token
+func (p *fileParser) sourceFrom(start int) string {
+	if p.pos <= start {
+		return ""
+	}
+	return p.src[p.tokens[start].pos:p.tokens[p.pos-1].end]
+}
 
-		// Skip function properties (e.g., errorBuilder: (context, error, stackTrace) { ... })
-		if strings.HasPrefix(propValue, "(") && strings.Contains(propValue, ") {") {
-			continue
+// parseBuildBody parses the body of a build method and returns the widget
+// built by its top-level return statement along with the statements
+// before it. The whole body is consumed.
+func (p *fileParser) parseBuildBody() (*ast.WidgetTree, error) {
+	if p.accept("=>") {
+		root, err := p.parseRootWidget()
+		if err != nil {
+			return nil, err
 		}
-
-		// Special handling for children: [...]
-		if propName == "children" && strings.HasPrefix(propValue, "[") && strings.HasSuffix(propValue, "]") {
-			children := p.parseWidgetList(propValue)
-			widget.Children = append(widget.Children, children...)
-			continue
+		if _, err := p.expect(";"); err != nil {
+			return nil, err
 		}
+		return &ast.WidgetTree{Root: root}, nil
+	}
 
-		// Handle nested widget
-		if widgetExpr := p.parseWidgetExpression(propValue); widgetExpr != nil {
-			widget.Properties[propName] = ast.PropertyValue{Widget: widgetExpr}
-			continue
-		}
-		// Handle list (not children)
-		if strings.HasPrefix(propValue, "[") && strings.HasSuffix(propValue, "]") {
-			// For other list properties, not 'children'
-			continue
-		}
-		// Handle string interpolation: replace with placeholder
-		if strings.Contains(propValue, "$") {
-			placeholder := "INTERPOLATED_STRING"
-			widget.Properties[propName] = ast.PropertyValue{String: &placeholder}
-			continue
+	bodyStart := p.pos
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	tree := &ast.WidgetTree{}
+	parseStmts := true
+	for !p.peek().is("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf(p.peek(), codeSyntax, "unexpected end of file in build method")
 		}
-		// Handle string
-		if (strings.HasPrefix(propValue, "'") && strings.HasSuffix(propValue, "'")) || (strings.HasPrefix(propValue, "\"") && strings.HasSuffix(propValue, "\"")) {
-			value := strings.Trim(propValue, "'\"")
-			widget.Properties[propName] = ast.PropertyValue{String: &value}
-			continue
+		if p.accept("return") {
+			root, err := p.parseRootWidget()
+			if err != nil {
+				return nil, err
+			}
+			tree.Root = root
+			p.pos = bodyStart
+			if err := p.skipBalanced(); err != nil {
+				return nil, err
+			}
+			return tree, nil
 		}
-		// Handle expressions like Theme.of(context), Colors.grey[300], BoxFit.cover
-		if strings.Contains(propValue, ".") || strings.Contains(propValue, "[") {
-			placeholder := propValue
-			widget.Properties[propName] = ast.PropertyValue{String: &placeholder}
+		if !parseStmts {
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
 			continue
 		}
-		// Fallback: treat as string
-		widget.Properties[propName] = ast.PropertyValue{String: &propValue}
-	}
-
-	return widget
-}
-
-// parseWidgetList parses a list of widgets or values
-func (p *Parser) parseWidgetList(list string) []*ast.WidgetNode {
-	list = strings.TrimSpace(list)
-	list = strings.TrimPrefix(list, "[")
-	list = strings.TrimSuffix(list, "]")
-
-	items := splitArgsTopLevel(list)
-	widgets := make([]*ast.WidgetNode, 0)
-	for _, item := range items {
-		item = strings.TrimSpace(item)
-		if item == "" {
+		stmt, err := p.parseStatement()
+		if err != nil {
+			// Recover by skipping the statements before the return, so
+			// that a construct the parser does not understand only costs
+			// the variables they declare
+			d := err.(diag.Diagnostic)
+			p.warnf(d.Span, codeUnsupportedElement, "statements of build method were skipped: %s", d.Message)
+			p.pos = bodyStart + 1
+			tree.Stmts, parseStmts = nil, false
 			continue
 		}
-		if widget := p.parseWidgetExpression(item); widget != nil {
-			widgets = append(widgets, widget)
+		if stmt != nil {
+			tree.Stmts = append(tree.Stmts, stmt)
 		}
 	}
-	return widgets
+	return nil, p.errorf(p.peek(), codeNoReturn, "build method does not return a widget")
 }
 
-// splitArgsTopLevel splits a comma-separated argument string at the top level only
-func splitArgsTopLevel(s string) []string {
-	var args []string
-	var current strings.Builder
-	paren, bracket := 0, 0
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		switch c {
-		case '(':
-			paren++
-		case ')':
-			paren--
-		case '[':
-			bracket++
-		case ']':
-			bracket--
-		case ',':
-			if paren == 0 && bracket == 0 {
-				args = append(args, current.String())
-				current.Reset()
-				continue
-			}
-		}
-		current.WriteByte(c)
+func (p *fileParser) parseRootWidget() (*ast.WidgetNode, error) {
+	start := p.pos
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
 	}
-	if current.Len() > 0 {
-		args = append(args, current.String())
+	call, ok := x.(*ast.Call)
+	if !ok || call.Widget == nil {
+		return nil, p.file.errorf(p.tokens[start].pos, p.tokens[p.pos-1].end, codeNotWidget,
+			"build method must return a widget constructor call")
 	}
-	return args
+	return p.lowerWidget(call, true), nil
 }
 
-// parsePropertyValue converts a property value to a PropertyValue
-func (p *Parser) parsePropertyValue(value *ast.PropertyValue) error {
-	switch {
-	case value.String != nil:
-		*value = ast.PropertyValue{String: value.String}
-	case value.Number != nil:
-		*value = ast.PropertyValue{Number: value.Number}
-	case value.Boolean != nil:
-		*value = ast.PropertyValue{Boolean: value.Boolean}
-	case value.Widget != nil:
-		widget, err := p.parseWidget(value.Widget)
-		if err != nil {
-			return err
-		}
-		*value = ast.PropertyValue{Widget: widget}
-	case value.List != nil:
-		items := make([]ast.PropertyValue, len(value.List))
-		for i, item := range value.List {
-			if err := p.parsePropertyValue(&item); err != nil {
+// skipStatement skips a single statement, including any nested blocks
+func (p *fileParser) skipStatement() error {
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(tok, codeSyntax, "unexpected end of file")
+		case tok.is(";"):
+			p.advance()
+			return nil
+		case tok.is("{"):
+			if err := p.skipBalanced(); err != nil {
 				return err
 			}
-			items[i] = item
-		}
-		*value = ast.PropertyValue{List: items}
-	case value.Style != nil:
-		styles := make(map[string]string)
-		for k, v := range value.Style {
-			styles[k] = v
+			// A block ends the statement unless more of it follows, as in
+			// `if (a) { ... } else { ... }` or `final f = () { ... };`
+			if next := p.peek(); !next.is("else") && !next.is(";") && !next.is(")") &&
+				!next.is(",") && !next.is("catch") && !next.is("finally") && !next.is("on") {
+				return nil
+			}
+		case tok.is("(") || tok.is("["):
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+		default:
+			p.advance()
 		}
-		*value = ast.PropertyValue{Style: styles}
-	default:
-		return fmt.Errorf("invalid property value")
 	}
-	return nil
 }
 
-// parseWidget converts a widget node to a WidgetNode
-func (p *Parser) parseWidget(node *ast.WidgetNode) (*ast.WidgetNode, error) {
-	if node == nil {
-		return nil, nil
-	}
-
-	// Parse properties
-	props := make(map[string]ast.PropertyValue)
-	for name, value := range node.Properties {
-		if err := p.parsePropertyValue(&value); err != nil {
-			return nil, err
-		}
-		props[name] = value
-	}
-
-	// Parse children
-	children := make([]*ast.WidgetNode, len(node.Children))
-	for i, child := range node.Children {
-		parsedChild, err := p.parseWidget(child)
-		if err != nil {
-			return nil, err
+// skipBalanced skips a bracketed group starting at the current token
+func (p *fileParser) skipBalanced() error {
+	open := p.advance()
+	var stack []string
+	stack = append(stack, closerFor(open.text))
+	for len(stack) > 0 {
+		tok := p.advance()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(open, codeSyntax, "unclosed %q", open.text)
+		case tok.is("(") || tok.is("[") || tok.is("{"):
+			stack = append(stack, closerFor(tok.text))
+		case tok.is(")") || tok.is("]") || tok.is("}"):
+			if tok.text != stack[len(stack)-1] {
+				return p.errorf(tok, codeSyntax, "expected %q but found %s", stack[len(stack)-1], describe(tok))
+			}
+			stack = stack[:len(stack)-1]
 		}
-		children[i] = parsedChild
 	}
-
-	return &ast.WidgetNode{
-		Name:       node.Name,
-		Properties: props,
-		Children:   children,
-	}, nil
+	return nil
 }
 
-// parseWidgetTree converts a widget tree to a WidgetTree
-func (p *Parser) parseWidgetTree(tree *ast.WidgetTree) (*ast.WidgetTree, error) {
-	if tree == nil {
-		return nil, nil
-	}
-
-	root, err := p.parseWidget(tree.Root)
-	if err != nil {
-		return nil, err
+func closerFor(open string) string {
+	switch open {
+	case "(":
+		return ")"
+	case "[":
+		return "]"
+	default:
+		return "}"
 	}
-
-	return &ast.WidgetTree{
-		Root: root,
-	}, nil
-}
-
-// Helper function to create string pointers
-func stringPtr(s string) *string {
-	return &s
 }
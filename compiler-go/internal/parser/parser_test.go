@@ -0,0 +1,306 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"compiler-go/internal/diag"
+	"compiler-go/pkg/ast"
+)
+
+// spanString formats a span as line:column-line:column
+func spanString(startLine, startColumn, endLine, endColumn int) string {
+	return fmt.Sprintf("%d:%d-%d:%d", startLine, startColumn, endLine, endColumn)
+}
+
+// parseExpr parses src as a single expression
+func parseExpr(src string) (ast.Expr, error) {
+	file := newSourceFile("test.dart", src)
+	tokens, err := tokenize(file)
+	if err != nil {
+		return nil, err
+	}
+	p := &fileParser{file: file, src: src, tokens: tokens}
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, codeSyntax, "unexpected %s after expression", describe(tok))
+	}
+	return x, nil
+}
+
+// format prints an expression with every operation parenthesized, so
+// that tests show how it was grouped
+func format(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.Literal:
+		if x.Kind == ast.StringLiteral {
+			return fmt.Sprintf("%q", x.Value)
+		}
+		return x.Value
+	case *ast.StringInterpolation:
+		parts := make([]string, len(x.Parts))
+		for i, part := range x.Parts {
+			parts[i] = format(part)
+		}
+		return "str(" + strings.Join(parts, " ") + ")"
+	case *ast.Ident:
+		return x.Name
+	case *ast.Paren:
+		return "paren" + format(x.X)
+	case *ast.Member:
+		if x.NullAware {
+			return format(x.X) + "?." + x.Name
+		}
+		return format(x.X) + "." + x.Name
+	case *ast.Index:
+		return format(x.X) + "[" + format(x.Index) + "]"
+	case *ast.Call:
+		args := make([]string, len(x.Args))
+		for i, arg := range x.Args {
+			args[i] = format(arg.Value)
+			if arg.Name != "" {
+				args[i] = arg.Name + ": " + args[i]
+			}
+		}
+		return format(x.Fun) + "(" + strings.Join(args, ", ") + ")"
+	case *ast.Unary:
+		if x.Postfix {
+			return "(" + format(x.X) + x.Op + ")"
+		}
+		if x.Op == "await" {
+			return "(await " + format(x.X) + ")"
+		}
+		return "(" + x.Op + format(x.X) + ")"
+	case *ast.Binary:
+		return "(" + format(x.X) + " " + x.Op + " " + format(x.Y) + ")"
+	case *ast.Conditional:
+		return "(" + format(x.Cond) + " ? " + format(x.Then) + " : " + format(x.Else) + ")"
+	case *ast.Assign:
+		return "(" + format(x.Target) + " " + x.Op + " " + format(x.Value) + ")"
+	case *ast.FuncLit:
+		params := make([]string, len(x.Params))
+		for i, param := range x.Params {
+			params[i] = param.Name
+			if param.Named {
+				params[i] = "{" + params[i] + "}"
+			}
+			if param.Default != nil {
+				params[i] += " = " + format(param.Default)
+			}
+		}
+		body := "=> " + format(x.Result)
+		if x.Body != nil {
+			body = "{" + fmt.Sprint(len(x.Body.Stmts)) + " statements}"
+		}
+		return "fn(" + strings.Join(params, ", ") + ") " + body
+	case *ast.ListLit:
+		elements := make([]string, len(x.Elements))
+		for i, element := range x.Elements {
+			elements[i] = format(element)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *ast.MapLit:
+		entries := make([]string, len(x.Entries))
+		for i, entry := range x.Entries {
+			entries[i] = format(entry.Value)
+			if entry.Key != nil {
+				entries[i] = format(entry.Key) + ": " + entries[i]
+			}
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	case *ast.RawExpr:
+		return "raw`" + x.Text + "`"
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+func TestParseExpr(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		// Precedence and associativity
+		{"a + b * c", "(a + (b * c))"},
+		{"a * b + c", "((a * b) + c)"},
+		{"a - b - c", "((a - b) - c)"},
+		{"a ~/ b % c", "((a ~/ b) % c)"},
+		{"a << 1 + b", "(a << (1 + b))"},
+		{"a >> b > c", "((a >> b) > c)"},
+		{"a >>> b", "(a >>> b)"},
+		{"a & b ^ c | d", "(((a & b) ^ c) | d)"},
+		{"a | b == c", "((a | b) == c)"},
+		{"a == b && c != d", "((a == b) && (c != d))"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a ?? b || c", "(a ?? (b || c))"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"a < b == c >= d", "((a < b) == (c >= d))"},
+		{"x is int && y is! String", "((x is int) && (y is! String))"},
+		{"x as List<int>", "(x as List<int>)"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a || b ? c + 1 : d", "((a || b) ? (c + 1) : d)"},
+		{"a = b = c", "(a = (b = c))"},
+		{"a += b ?? 1", "(a += (b ?? 1))"},
+		{"a ~/= 2", "(a ~/= 2)"},
+		{"(a + b) * c", "(paren(a + b) * c)"},
+
+		// Unary and postfix operators
+		{"-a * b", "((-a) * b)"},
+		{"-a.b", "(-a.b)"},
+		{"!a && !b", "((!a) && (!b))"},
+		{"- -a", "(-(-a))"},
+		{"~a", "(~a)"},
+		{"a++ + ++b", "((a++) + (++b))"},
+		{"a! + b", "((a!) + b)"},
+		{"await f() + 1", "((await f()) + 1)"},
+
+		// Selectors
+		{"a.b.c", "a.b.c"},
+		{"a?.b.c(d, e: f)[0]", "a?.b.c(d, e: f)[0]"},
+		{"f<int>(a)", "f(a)"},
+		{"const Text('a')", `Text("a")`},
+		{"new Foo.bar()", "Foo.bar()"},
+
+		// Literals
+		{"[1, 2.5, true, null]", "[1, 2.5, true, null]"},
+		{"<int>[]", "[]"},
+		{"{'a': 1, 'b': [2]}", `{"a": 1, "b": [2]}`},
+		{"{a, b}", "{a, b}"},
+
+		// Function literals
+		{"() => 1", "fn() => 1"},
+		{"(a, {b = 2}) { a; b; }", "fn(a, {b} = 2) {2 statements}"},
+		{"(v) async { await v; }", "fn(v) {1 statements}"},
+
+		// Cascades are kept as source text
+		{"a..b = 1..c()", "raw`a..b = 1..c()`"},
+		{"a?..b()..c", "raw`a?..b()..c`"},
+
+		// Collection if, for and spread elements are kept as source text
+		{"[1, if (a) 2 else 3, for (var i in xs) i * 2, ...ys, ...?zs]",
+			"[1, raw`if (a) 2 else 3`, raw`for (var i in xs) i * 2`, raw`...ys`, raw`...?zs`]"},
+		{"[if (a) Text('x'), for (final b in [1, 2]) if (b > 1) b]",
+			"[raw`if (a) Text('x')`, raw`for (final b in [1, 2]) if (b > 1) b`]"},
+		{"{'a': 1, if (b) 'c': 2}", "{\"a\": 1, raw`if (b) 'c': 2`}"},
+	}
+	for _, tt := range tests {
+		x, err := parseExpr(tt.src)
+		if err != nil {
+			t.Errorf("parse %q: %v", tt.src, err)
+			continue
+		}
+		if got := format(x); got != tt.want {
+			t.Errorf("parse %q\n got %s\nwant %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		// Escapes
+		{`'a\nb\tc'`, `"a\nb\tc"`},
+		{`'it\'s ' "say \"hi\""`, `"it's say \"hi\""`},
+		{`'\x41B\u{1F600}\$\\'`, `"AB😀$\\"`},
+		{`'\q'`, `"q"`},
+
+		// Raw strings
+		{`r'a\n$b'`, `"a\\n$b"`},
+		{`R"${c}"`, `"${c}"`},
+
+		// Multi-line strings drop a blank first line only
+		{"'''\n  a\n  b'''", `"  a\n  b"`},
+		{"'''  \nx'''", `"x"`},
+		{"\"\"\"a\n'b'\"\"\"", `"a\n'b'"`},
+		{"r'''\n\\n'''", `"\\n"`},
+
+		// Adjacent strings are concatenated
+		{`'a' "b" r'\c'`, `"ab\\c"`},
+		{"'a'\n    'b'", `"ab"`},
+
+		// Interpolation
+		{`'x $a y'`, `str("x " a " y")`},
+		{`'${a + 1}'`, `str((a + 1))`},
+		{`'$a.b $c'`, `str(a ".b " c)`},
+		{`'${a.b} ${f('}')}'`, `str(a.b " " f("}"))`},
+		{`'$_x$y'`, `str(_x y)`},
+		{`'a $b' 'c' "${d}"`, `str("a " b "c" d)`},
+		{`'\$a $b'`, `str("$a " b)`},
+	}
+	for _, tt := range tests {
+		x, err := parseExpr(tt.src)
+		if err != nil {
+			t.Errorf("parse %q: %v", tt.src, err)
+			continue
+		}
+		if got := format(x); got != tt.want {
+			t.Errorf("parse %q\n got %s\nwant %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseExprSpans(t *testing.T) {
+	src := "a +\n  b.c(d)"
+	x, err := parseExpr(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := x.(*ast.Binary)
+	tests := []struct {
+		node ast.Node
+		want string
+	}{
+		{binary, "1:1-2:9"},
+		{binary.X, "1:1-1:2"},
+		{binary.Y, "2:3-2:9"},
+		{binary.Y.(*ast.Call).Fun, "2:3-2:6"},
+		{binary.Y.(*ast.Call).Args[0].Value, "2:7-2:8"},
+	}
+	for _, tt := range tests {
+		span := tt.node.NodeSpan()
+		if got := spanString(span.Start.Line, span.Start.Column, span.End.Line, span.End.Column); got != tt.want {
+			t.Errorf("%s spans %s, want %s", format(tt.node.(ast.Expr)), got, tt.want)
+		}
+	}
+}
+
+func TestParseUnitErrors(t *testing.T) {
+	const widget = "class A extends StatelessWidget {\n  Widget build(BuildContext context) {\n    return %s;\n  }\n}\n"
+	tests := []struct {
+		src  string
+		code string
+		span string
+	}{
+		{fmt.Sprintf(widget, "Text('a' +)"), codeSyntax, "3:22-3:23"},
+		{fmt.Sprintf(widget, "Text('a'"), codeSyntax, "3:20-3:21"},
+		{fmt.Sprintf(widget, "Text(a.)"), codeSyntax, "3:19-3:20"},
+		{fmt.Sprintf(widget, "Text('a)"), codeUnterminated, "3:17-3:21"},
+		{fmt.Sprintf(widget, "Text(`a`)"), codeUnexpectedChar, "3:17-3:18"},
+		{"class A extends StatelessWidget {\n  int x = 1;\n}\n", codeNoBuildMethod, "1:7-1:8"},
+		{"class A extends StatefulWidget {\n  State<A> createState() => B();\n}\n", codeNoStateClass, "1:7-1:8"},
+	}
+	for _, tt := range tests {
+		unit, diags := NewParser().ParseUnit("test.dart", tt.src)
+		if unit != nil {
+			t.Errorf("parse %q returned a unit despite errors %v", tt.src, diags)
+		}
+		var d *diag.Diagnostic
+		for i := range diags {
+			if diags[i].Severity == diag.Error {
+				d = &diags[i]
+				break
+			}
+		}
+		if d == nil {
+			t.Errorf("parse %q reported no error, want %s", tt.src, tt.code)
+			continue
+		}
+		got := spanString(d.Span.Start.Line, d.Span.Start.Column, d.Span.End.Line, d.Span.End.Column)
+		if d.Code != tt.code || got != tt.span {
+			t.Errorf("parse %q reported %s %q at %s, want %s at %s", tt.src, d.Code, d.Message, got, tt.code, tt.span)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"compiler-go/internal/diag"
+	"compiler-go/pkg/ast"
+)
+
+// Diagnostic codes reported by the parser
+const (
+	codeUnexpectedChar     = "P001"
+	codeUnterminated       = "P002"
+	codeSyntax             = "P003"
+	codeNoBuildMethod      = "P004"
+	codeNoReturn           = "P005"
+	codeNotWidget          = "P006"
+	codeUnsupportedElement = "P007"
+	codeNoStateClass       = "P008"
+)
+
+// sourceFile maps byte offsets in a file to line and column positions
+type sourceFile struct {
+	name       string
+	text       string
+	lineStarts []int
+}
+
+func newSourceFile(name, text string) *sourceFile {
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &sourceFile{name: name, text: text, lineStarts: lineStarts}
+}
+
+// position converts a byte offset to a Position
+func (f *sourceFile) position(offset int) ast.Position {
+	line := sort.Search(len(f.lineStarts), func(i int) bool { return f.lineStarts[i] > offset }) - 1
+	return ast.Position{Offset: offset, Line: line + 1, Column: offset - f.lineStarts[line] + 1}
+}
+
+// span returns the span covering the byte range [start, end)
+func (f *sourceFile) span(start, end int) ast.Span {
+	return ast.Span{File: f.name, Start: f.position(start), End: f.position(end)}
+}
+
+// errorf creates an error diagnostic for the byte range [start, end)
+func (f *sourceFile) errorf(start, end int, code, format string, args ...interface{}) diag.Diagnostic {
+	return diag.Errorf(f.span(start, end), code, format, args...)
+}
+
+// describe returns a short human-readable description of a token
+func describe(tok token) string {
+	if tok.kind == tokenEOF {
+		return "end of file"
+	}
+	text := tok.text
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i] + "..."
+	}
+	return fmt.Sprintf("%q", text)
+}
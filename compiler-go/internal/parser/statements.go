@@ -0,0 +1,312 @@
+package parser
+
+import (
+	"compiler-go/pkg/ast"
+)
+
+// parseBlock parses a brace-delimited list of statements
+func (p *fileParser) parseBlock() (*ast.Block, error) {
+	start := p.pos
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	block := &ast.Block{}
+	for !p.accept("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf(p.peek(), codeSyntax, "unexpected end of file in block")
+		}
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		if stmt != nil {
+			block.Stmts = append(block.Stmts, stmt)
+		}
+	}
+	block.Span = p.spanFrom(start)
+	return block, nil
+}
+
+// parseStatement parses a single statement. Statements without a
+// structured representation, such as switch and try, are kept as source
+// text. Empty statements are reported as nil.
+func (p *fileParser) parseStatement() (ast.Stmt, error) {
+	start := p.pos
+	tok := p.peek()
+	switch {
+	case tok.is(";"):
+		p.advance()
+		return nil, nil
+	case tok.is("{"):
+		return p.parseBlock()
+	case tok.is("if"):
+		return p.parseIf()
+	case tok.is("for"):
+		return p.parseFor()
+	case tok.is("while"):
+		p.advance()
+		cond, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.parseLoopBody()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.While{Cond: cond, Body: body, Span: p.spanFrom(start)}, nil
+	case tok.is("return"):
+		p.advance()
+		ret := &ast.Return{}
+		if !p.peek().is(";") {
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			ret.Value = value
+		}
+		if _, err := p.expect(";"); err != nil {
+			return nil, err
+		}
+		ret.Span = p.spanFrom(start)
+		return ret, nil
+	case (tok.is("break") || tok.is("continue")) && p.peekAt(1).is(";"):
+		p.advance()
+		p.advance()
+		return &ast.Branch{Keyword: tok.text, Span: p.spanFrom(start)}, nil
+	case tok.is("switch") || tok.is("try") || tok.is("do") || tok.is("assert") ||
+		tok.is("yield") || tok.is("rethrow") || (tok.kind == tokenIdent && p.peekAt(1).is(":")):
+		if err := p.skipStatement(); err != nil {
+			return nil, err
+		}
+		return &ast.RawStmt{Text: p.sourceFrom(start), Span: p.spanFrom(start)}, nil
+	}
+
+	if decl, ok, err := p.tryParseVarDecl(); err != nil || ok {
+		return decl, err
+	}
+	if p.atLocalFunction() {
+		if err := p.skipStatement(); err != nil {
+			return nil, err
+		}
+		return &ast.RawStmt{Text: p.sourceFrom(start), Span: p.spanFrom(start)}, nil
+	}
+
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(";"); err != nil {
+		return nil, err
+	}
+	return &ast.ExprStmt{X: x, Span: p.spanFrom(start)}, nil
+}
+
+// parseCondition parses a parenthesized condition
+func (p *fileParser) parseCondition() (ast.Expr, error) {
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
+	cond, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return cond, nil
+}
+
+func (p *fileParser) parseIf() (ast.Stmt, error) {
+	start := p.pos
+	p.advance()
+	cond, err := p.parseCondition()
+	if err != nil {
+		return nil, err
+	}
+	then, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &ast.If{Cond: cond, Then: then}
+	if p.accept("else") {
+		if stmt.Else, err = p.parseLoopBody(); err != nil {
+			return nil, err
+		}
+	}
+	stmt.Span = p.spanFrom(start)
+	return stmt, nil
+}
+
+// parseLoopBody parses the body of an if statement or loop, reporting an
+// empty statement as an empty block
+func (p *fileParser) parseLoopBody() (ast.Stmt, error) {
+	start := p.pos
+	body, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return &ast.Block{Span: p.spanFrom(start)}, nil
+	}
+	return body, nil
+}
+
+// parseFor parses a C-style for loop or a for-in loop
+func (p *fileParser) parseFor() (ast.Stmt, error) {
+	start := p.pos
+	p.advance()
+	if p.peek().is("await") {
+		if err := p.skipStatement(); err != nil {
+			return nil, err
+		}
+		return &ast.RawStmt{Text: p.sourceFrom(start), Span: p.spanFrom(start)}, nil
+	}
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	// for (final item in items)
+	if name, ok := p.matchForInVariable(); ok {
+		iterable, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		body, err := p.parseLoopBody()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ForIn{Name: name, Iterable: iterable, Body: body, Span: p.spanFrom(start)}, nil
+	}
+
+	loop := &ast.For{}
+	if !p.accept(";") {
+		init, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		loop.Init = init
+	}
+	if !p.peek().is(";") {
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		loop.Cond = cond
+	}
+	if _, err := p.expect(";"); err != nil {
+		return nil, err
+	}
+	for !p.accept(")") {
+		update, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		loop.Update = append(loop.Update, update)
+		if !p.accept(",") {
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	body, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	loop.Body = body
+	loop.Span = p.spanFrom(start)
+	return loop, nil
+}
+
+// matchForInVariable consumes the loop variable and `in` keyword of a
+// for-in loop, returning the variable's name
+func (p *fileParser) matchForInVariable() (string, bool) {
+	save := p.pos
+	for p.peek().is("final") || p.peek().is("var") || p.peek().is("const") {
+		p.advance()
+	}
+	if p.peek().kind == tokenIdent && p.peekAt(1).is("in") {
+		name := p.advance().text
+		p.advance()
+		return name, true
+	}
+	if _, err := p.parseType(); err == nil && p.peek().kind == tokenIdent && p.peekAt(1).is("in") {
+		name := p.advance().text
+		p.advance()
+		return name, true
+	}
+	p.pos = save
+	return "", false
+}
+
+// tryParseVarDecl parses a local variable declaration such as
+// `var a = 1;`, `final int b;` or `List<String> c = [], d = [];`
+func (p *fileParser) tryParseVarDecl() (ast.Stmt, bool, error) {
+	start := p.pos
+	final, hasModifier := false, false
+	for p.peek().is("final") || p.peek().is("const") || p.peek().is("var") || p.peek().is("late") {
+		hasModifier = true
+		if tok := p.advance(); tok.is("final") || tok.is("const") {
+			final = true
+		}
+	}
+
+	typ := ""
+	if !(p.peek().kind == tokenIdent && isDeclarationEnd(p.peekAt(1))) {
+		save := p.pos
+		parsed, err := p.parseType()
+		if err != nil || p.peek().kind != tokenIdent || !isDeclarationEnd(p.peekAt(1)) {
+			if hasModifier {
+				return nil, false, p.errorf(p.peek(), codeSyntax, "expected variable name but found %s", describe(p.peek()))
+			}
+			p.pos = save
+			return nil, false, nil
+		}
+		typ = parsed
+	} else if !hasModifier {
+		// `a = b;` is an assignment, not a declaration
+		p.pos = start
+		return nil, false, nil
+	}
+
+	decl := &ast.VarDecl{Type: typ, Final: final}
+	for {
+		spec := ast.VarSpec{Name: p.advance().text}
+		if p.accept("=") {
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, false, err
+			}
+			spec.Value = value
+		}
+		decl.Vars = append(decl.Vars, spec)
+		if !p.accept(",") {
+			break
+		}
+		if p.peek().kind != tokenIdent {
+			return nil, false, p.errorf(p.peek(), codeSyntax, "expected variable name but found %s", describe(p.peek()))
+		}
+	}
+	if _, err := p.expect(";"); err != nil {
+		return nil, false, err
+	}
+	decl.Span = p.spanFrom(start)
+	return decl, true, nil
+}
+
+// atLocalFunction reports whether a local function declaration such as
+// `void helper() { ... }` starts at the current token
+func (p *fileParser) atLocalFunction() bool {
+	save := p.pos
+	defer func() { p.pos = save }()
+	if p.peek().kind == tokenIdent && p.peekAt(1).kind == tokenIdent && p.peekAt(2).is("(") {
+		return true
+	}
+	if _, err := p.parseType(); err != nil {
+		return false
+	}
+	return p.peek().kind == tokenIdent && p.peekAt(1).is("(")
+}
@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"compiler-go/pkg/ast"
+)
+
+// parseStringLiteral parses one or more adjacent string literals, which
+// Dart concatenates. A string without interpolation is returned as a
+// literal holding its decoded contents.
+func (p *fileParser) parseStringLiteral() (ast.Expr, error) {
+	start := p.pos
+	var parts []ast.Expr
+	for p.peek().kind == tokenString {
+		tokParts, err := p.decodeString(p.advance())
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range tokParts {
+			// Text that ends one literal and starts the next is merged
+			if literal, ok := part.(*ast.Literal); ok && len(parts) > 0 {
+				if prev, ok := parts[len(parts)-1].(*ast.Literal); ok {
+					parts[len(parts)-1] = &ast.Literal{
+						Kind:  ast.StringLiteral,
+						Value: prev.Value + literal.Value,
+						Span:  ast.Span{File: prev.Span.File, Start: prev.Span.Start, End: literal.Span.End},
+					}
+					continue
+				}
+			}
+			parts = append(parts, part)
+		}
+	}
+
+	span := p.spanFrom(start)
+	switch {
+	case len(parts) == 0:
+		return &ast.Literal{Kind: ast.StringLiteral, Span: span}, nil
+	case len(parts) == 1:
+		if literal, ok := parts[0].(*ast.Literal); ok {
+			return &ast.Literal{Kind: ast.StringLiteral, Value: literal.Value, Span: span}, nil
+		}
+	}
+	return &ast.StringInterpolation{Parts: parts, Span: span}, nil
+}
+
+// decodeString splits a string token into its decoded text and its
+// interpolated expressions
+func (p *fileParser) decodeString(tok token) ([]ast.Expr, error) {
+	text, offset := tok.text, tok.pos
+	raw := text[0] == 'r' || text[0] == 'R'
+	if raw {
+		text, offset = text[1:], offset+1
+	}
+	quote := text[:1]
+	if strings.HasPrefix(text, strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	body := text[len(quote) : len(text)-len(quote)]
+	base := offset + len(quote)
+
+	i := 0
+	if len(quote) == 3 {
+		// A multi-line string drops its first line if it is blank
+		j := 0
+		for j < len(body) && (body[j] == ' ' || body[j] == '\t') {
+			j++
+		}
+		if strings.HasPrefix(body[j:], "\n") {
+			i = j + 1
+		} else if strings.HasPrefix(body[j:], "\r\n") {
+			i = j + 2
+		}
+	}
+
+	var parts []ast.Expr
+	var literal strings.Builder
+	literalStart := i
+	flush := func(end int) {
+		if literal.Len() > 0 {
+			parts = append(parts, &ast.Literal{
+				Kind:  ast.StringLiteral,
+				Value: literal.String(),
+				Span:  p.file.span(base+literalStart, base+end),
+			})
+			literal.Reset()
+		}
+	}
+	for i < len(body) {
+		if literal.Len() == 0 {
+			literalStart = i
+		}
+		c := body[i]
+		switch {
+		case raw:
+			literal.WriteByte(c)
+			i++
+		case c == '\\':
+			decoded, width := decodeEscape(body[i+1:])
+			literal.WriteString(decoded)
+			i += 1 + width
+		case c == '$' && strings.HasPrefix(body[i:], "${"):
+			flush(i)
+			x, end, err := p.parseInterpolation(base + i + 2)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, x)
+			i = end + 1 - base
+		case c == '$' && i+1 < len(body) && isIdentStart(body[i+1]) && body[i+1] != '$':
+			flush(i)
+			j := i + 1
+			for j < len(body) && isIdentPart(body[j]) && body[j] != '$' {
+				j++
+			}
+			parts = append(parts, &ast.Ident{Name: body[i+1 : j], Span: p.file.span(base+i+1, base+j)})
+			i = j
+		default:
+			literal.WriteByte(c)
+			i++
+		}
+	}
+	flush(len(body))
+	return parts, nil
+}
+
+// decodeEscape decodes the escape sequence following a backslash,
+// returning the decoded text and the number of bytes consumed
+func decodeEscape(s string) (string, int) {
+	if s == "" {
+		return `\`, 0
+	}
+	switch s[0] {
+	case 'n':
+		return "\n", 1
+	case 'r':
+		return "\r", 1
+	case 't':
+		return "\t", 1
+	case 'b':
+		return "\b", 1
+	case 'f':
+		return "\f", 1
+	case 'v':
+		return "\v", 1
+	case 'x':
+		if len(s) >= 3 && isHexDigit(s[1]) && isHexDigit(s[2]) {
+			n, _ := strconv.ParseUint(s[1:3], 16, 32)
+			return string(rune(n)), 3
+		}
+	case 'u':
+		// \u{1F600} or \u00E9
+		if end := strings.IndexByte(s, '}'); strings.HasPrefix(s, "u{") && end > 2 {
+			if n, err := strconv.ParseUint(s[2:end], 16, 32); err == nil {
+				return string(rune(n)), end + 1
+			}
+		}
+		if len(s) >= 5 {
+			if n, err := strconv.ParseUint(s[1:5], 16, 32); err == nil {
+				return string(rune(n)), 5
+			}
+		}
+	}
+	// Any other escaped character stands for itself
+	_, width := utf8.DecodeRuneInString(s)
+	return s[:width], width
+}
+
+// parseInterpolation parses the expression of a ${...} interpolation
+// starting at offset, returning it with the offset of the closing brace
+func (p *fileParser) parseInterpolation(offset int) (ast.Expr, int, error) {
+	l := &lexer{file: p.file, src: p.src, pos: offset}
+	var tokens []token
+	depth := 1
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, 0, err
+		}
+		if tok.kind == tokenEOF {
+			return nil, 0, p.file.errorf(offset-2, offset, codeUnterminated, "unterminated string interpolation")
+		}
+		if tok.is("{") {
+			depth++
+		} else if tok.is("}") {
+			depth--
+			if depth == 0 {
+				tokens = append(tokens, token{kind: tokenEOF, pos: tok.pos, end: tok.pos})
+				break
+			}
+		}
+		tokens = append(tokens, tok)
+	}
+
+	sub := &fileParser{file: p.file, src: p.src, tokens: tokens}
+	x, err := sub.parseExpr()
+	p.diags = append(p.diags, sub.diags...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tok := sub.peek(); tok.kind != tokenEOF {
+		return nil, 0, sub.errorf(tok, codeSyntax, "unexpected %s in string interpolation", describe(tok))
+	}
+	end := tokens[len(tokens)-1].pos
+	return x, end, nil
+}
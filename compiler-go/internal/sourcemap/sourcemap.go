@@ -0,0 +1,190 @@
+// Package sourcemap builds source maps in the Source Map Revision 3
+// format. Code generators embed marks naming the source position that
+// the code after each mark was generated from; Extract removes the marks
+// and New encodes the positions they held.
+package sourcemap
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Marks are delimited by NUL bytes, which generated code never contains
+// otherwise: generators escape them in string literals.
+const (
+	markDelim = "\x00"
+	markSep   = "\x01"
+)
+
+// Mark returns a mark of the byte offset in the source file path
+func Mark(path string, offset int) string {
+	return markDelim + path + markSep + strconv.Itoa(offset) + markDelim
+}
+
+// Mapping ties a position in generated code to a position in a source
+// file
+type Mapping struct {
+	Line   int // line in the generated code, starting at 0
+	Column int // column in the generated code in UTF-16 code units, starting at 0
+	Source string
+	Offset int // byte offset in Source
+}
+
+// Extract returns code without its marks and the positions they marked,
+// in order. Of several marks at the same position, the last is kept.
+func Extract(code string) (string, []Mapping) {
+	var b strings.Builder
+	var mappings []Mapping
+	line, column := 0, 0
+	for {
+		i := strings.Index(code, markDelim)
+		if i < 0 {
+			break
+		}
+		line, column = advance(line, column, code[:i])
+		b.WriteString(code[:i])
+		end := strings.Index(code[i+1:], markDelim)
+		if end < 0 {
+			code = code[i+1:]
+			break
+		}
+		mark := code[i+1 : i+1+end]
+		code = code[i+2+end:]
+
+		source, offset, ok := strings.Cut(mark, markSep)
+		n, err := strconv.Atoi(offset)
+		if !ok || err != nil {
+			continue
+		}
+		m := Mapping{Line: line, Column: column, Source: source, Offset: n}
+		if last := len(mappings) - 1; last >= 0 && mappings[last].Line == line && mappings[last].Column == column {
+			mappings[last] = m
+		} else {
+			mappings = append(mappings, m)
+		}
+	}
+	b.WriteString(code)
+	return b.String(), mappings
+}
+
+// advance returns the position after text starting at line and column
+func advance(line, column int, text string) (int, int) {
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		line += strings.Count(text, "\n")
+		column = 0
+		text = text[i+1:]
+	}
+	return line, column + utf16Len(text)
+}
+
+// utf16Len returns the length of s in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2 // a surrogate pair
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// Map is a source map, which marshals to its JSON form
+type Map struct {
+	Version        int      `json:"version"`
+	File           string   `json:"file"`
+	Sources        []string `json:"sources"`
+	SourcesContent []string `json:"sourcesContent"`
+	Names          []string `json:"names"`
+	Mappings       string   `json:"mappings"`
+}
+
+// New returns the source map of the generated file named file. For each
+// source file of mappings, source returns its URL relative to the map
+// and its content, or false to leave out the mappings into it.
+func New(file string, mappings []Mapping, source func(path string) (url, content string, ok bool)) *Map {
+	m := &Map{Version: 3, File: file, Sources: []string{}, SourcesContent: []string{}, Names: []string{}}
+
+	type sourceFile struct {
+		index int
+		lines []int // byte offset of the start of each line
+		text  string
+	}
+	sources := make(map[string]*sourceFile)
+	lookup := func(path string) *sourceFile {
+		if s, ok := sources[path]; ok {
+			return s
+		}
+		url, content, ok := source(path)
+		if !ok {
+			sources[path] = nil
+			return nil
+		}
+		s := &sourceFile{index: len(m.Sources), lines: []int{0}, text: content}
+		for i := 0; i < len(content); i++ {
+			if content[i] == '\n' {
+				s.lines = append(s.lines, i+1)
+			}
+		}
+		m.Sources = append(m.Sources, url)
+		m.SourcesContent = append(m.SourcesContent, content)
+		sources[path] = s
+		return s
+	}
+
+	// Each segment holds the generated column, relative to the previous
+	// segment on the line, then the source index, line and column, each
+	// relative to the previous segment
+	var b strings.Builder
+	line := 0
+	var prevColumn, prevSource, prevSourceLine, prevSourceColumn int
+	first := true
+	for _, mapping := range mappings {
+		s := lookup(mapping.Source)
+		if s == nil || mapping.Offset < 0 || mapping.Offset > len(s.text) {
+			continue
+		}
+		for ; line < mapping.Line; line++ {
+			b.WriteByte(';')
+			prevColumn, first = 0, true
+		}
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		sourceLine := sort.Search(len(s.lines), func(i int) bool { return s.lines[i] > mapping.Offset }) - 1
+		sourceColumn := utf16Len(s.text[s.lines[sourceLine]:mapping.Offset])
+		writeVLQ(&b, mapping.Column-prevColumn)
+		writeVLQ(&b, s.index-prevSource)
+		writeVLQ(&b, sourceLine-prevSourceLine)
+		writeVLQ(&b, sourceColumn-prevSourceColumn)
+		prevColumn, prevSource, prevSourceLine, prevSourceColumn = mapping.Column, s.index, sourceLine, sourceColumn
+	}
+	m.Mappings = b.String()
+	return m
+}
+
+const base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// writeVLQ writes n as a base 64 variable-length quantity, whose lowest
+// bit is the sign and whose digits hold five bits each, lowest first,
+// with the sixth bit set on all but the last
+func writeVLQ(b *strings.Builder, n int) {
+	v := n << 1
+	if n < 0 {
+		v = -n<<1 | 1
+	}
+	for {
+		digit := v & 31
+		v >>= 5
+		if v > 0 {
+			digit |= 32
+		}
+		b.WriteByte(base64Digits[digit])
+		if v == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package sourcemap_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"compiler-go/internal/generator"
+	"compiler-go/internal/minify"
+	"compiler-go/internal/parser"
+	"compiler-go/internal/sourcemap"
+	"compiler-go/pkg/ast"
+)
+
+// segment is a decoded mapping segment, with absolute positions
+type segment struct {
+	line, column                     int
+	source, sourceLine, sourceColumn int
+}
+
+// decode decodes the mappings of a source map
+func decode(t *testing.T, mappings string) []segment {
+	t.Helper()
+	const digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	var segments []segment
+	var prev segment
+	for line, text := range strings.Split(mappings, ";") {
+		prev.column = 0
+		if text == "" {
+			continue
+		}
+		for _, field := range strings.Split(text, ",") {
+			var values []int
+			for v, shift := 0, 0; field != ""; field = field[1:] {
+				digit := strings.IndexByte(digits, field[0])
+				if digit < 0 {
+					t.Fatalf("invalid digit %q in mappings %q", field[0], mappings)
+				}
+				v |= digit & 31 << shift
+				shift += 5
+				if digit&32 == 0 {
+					n := v >> 1
+					if v&1 != 0 {
+						n = -n
+					}
+					values = append(values, n)
+					v, shift = 0, 0
+				}
+			}
+			if len(values) != 4 {
+				t.Fatalf("segment %q of mappings %q has %d fields, want 4", field, mappings, len(values))
+			}
+			prev = segment{
+				line:         line,
+				column:       prev.column + values[0],
+				source:       prev.source + values[1],
+				sourceLine:   prev.sourceLine + values[2],
+				sourceColumn: prev.sourceColumn + values[3],
+			}
+			segments = append(segments, prev)
+		}
+	}
+	return segments
+}
+
+// position returns the line and column, starting at 0, of the first
+// occurrence of needle in text, which must be ASCII up to it
+func position(t *testing.T, text, needle string) (int, int) {
+	t.Helper()
+	i := strings.Index(text, needle)
+	if i < 0 {
+		t.Fatalf("%q not found in\n%s", needle, text)
+	}
+	line := strings.Count(text[:i], "\n")
+	return line, i - strings.LastIndexByte(text[:i], '\n') - 1
+}
+
+func TestNew(t *testing.T) {
+	source := "a\nbé😀c\n\nd"
+	mappings := []sourcemap.Mapping{
+		{Line: 0, Column: 4, Source: "a.dart", Offset: 0},
+		{Line: 0, Column: 10, Source: "a.dart", Offset: strings.Index(source, "c")},
+		{Line: 0, Column: 12, Source: "missing.dart", Offset: 0},
+		{Line: 3, Column: 2, Source: "a.dart", Offset: strings.Index(source, "d")},
+		{Line: 3, Column: 40, Source: "a.dart", Offset: strings.Index(source, "b")},
+	}
+	m := sourcemap.New("a.js", mappings, func(path string) (string, string, bool) {
+		return "src/" + path, source, path == "a.dart"
+	})
+	if len(m.Sources) != 1 || m.Sources[0] != "src/a.dart" || m.SourcesContent[0] != source {
+		t.Errorf("sources = %q with content %q, want [src/a.dart] with the source", m.Sources, m.SourcesContent)
+	}
+	want := []segment{
+		{0, 4, 0, 0, 0},
+		{0, 10, 0, 1, 4}, // é is one UTF-16 code unit and 😀 two
+		{3, 2, 0, 3, 0},
+		{3, 40, 0, 1, 0},
+	}
+	got := decode(t, m.Mappings)
+	if len(got) != len(want) {
+		t.Fatalf("decoded %v from %q, want %v", got, m.Mappings, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("segment %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	code := "a" + sourcemap.Mark("x.dart", 1) + "b\n😀" + sourcemap.Mark("x.dart", 2) + sourcemap.Mark("x.dart", 3) + "c"
+	got, mappings := sourcemap.Extract(code)
+	if got != "ab\n😀c" {
+		t.Errorf("code = %q, want %q", got, "ab\n😀c")
+	}
+	want := []sourcemap.Mapping{
+		{Line: 0, Column: 1, Source: "x.dart", Offset: 1},
+		{Line: 1, Column: 2, Source: "x.dart", Offset: 3},
+	}
+	if len(mappings) != len(want) {
+		t.Fatalf("mappings = %v, want %v", mappings, want)
+	}
+	for i := range want {
+		if mappings[i] != want[i] {
+			t.Errorf("mapping %d = %v, want %v", i, mappings[i], want[i])
+		}
+	}
+}
+
+// TestGolden compiles the entry of the generator's golden tests with
+// source maps and checks that code generated from widget constructors,
+// callbacks, methods and statements maps to their Dart positions, before
+// and after minification
+func TestGolden(t *testing.T) {
+	const dir = "../generator/testdata/golden"
+	paths, err := filepath.Glob(filepath.Join(dir, "*.dart"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	units := make([]*ast.CompilationUnit, len(paths))
+	sources := make(map[string]string)
+	modules := make(map[string]string)
+	symbols := generator.NewSymbolTable()
+	entry := -1
+	for i, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unit, diags := parser.NewParser().ParseUnit(path, string(source))
+		if err := diags.Err(); err != nil {
+			t.Fatal(err)
+		}
+		units[i] = unit
+		sources[path] = string(source)
+		modules[path] = filepath.Join("out", strings.TrimSuffix(filepath.Base(path), ".dart")+".js")
+		symbols.AddUnit(unit)
+		if unit.Entry != nil {
+			entry = i
+		}
+	}
+	if entry < 0 {
+		t.Fatal("no golden input calls runApp")
+	}
+	path := paths[entry]
+
+	g := generator.NewJSGenerator()
+	g.SetSourceDir(dir)
+	g.SetSymbols(symbols)
+	marked, err := g.GenerateModule(units[entry], generator.ModuleOptions{
+		Path:      modules[path],
+		Runtime:   filepath.Join("out", generator.RuntimeModule),
+		Entry:     true,
+		Package:   "golden",
+		Modules:   modules,
+		SourceMap: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		js, minified, dart string
+	}{
+		{"this.Scaffold(", "this.Scaffold(", "Scaffold("},
+		{"this.TextField(", "this.TextField(", "TextField("},
+		{"new CountBadge(", "new CountBadge(", "CountBadge(count"},
+		{"this.FloatingActionButton(", "this.FloatingActionButton(", "FloatingActionButton("},
+		{"(value) =>", "(a)=>", "(value) =>"},
+		{"this.setState(() => {", "this.setState(()=>{", "setState(() {"},
+		{"this._add(", "this._add(", "_add(by"},
+	}
+	minified, err := minify.JS(marked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, build := range []struct {
+		name, code string
+	}{{"generated", marked}, {"minified", minified}} {
+		t.Run(build.name, func(t *testing.T) {
+			code, mappings := sourcemap.Extract(build.code)
+			m := sourcemap.New("app.js", mappings, func(source string) (string, string, bool) {
+				content, ok := sources[source]
+				return filepath.Base(source), content, ok
+			})
+			if len(m.Sources) != 1 || m.Sources[0] != filepath.Base(path) {
+				t.Fatalf("sources = %q, want [%s]", m.Sources, filepath.Base(path))
+			}
+			segments := decode(t, m.Mappings)
+			for _, tt := range tests {
+				js := tt.js
+				if build.name == "minified" {
+					js = tt.minified
+				}
+				line, column := position(t, code, js)
+				sourceLine, sourceColumn := position(t, sources[path], tt.dart)
+				found := false
+				for _, s := range segments {
+					if s.line == line && s.column == column {
+						found = true
+						if s.sourceLine != sourceLine || s.sourceColumn != sourceColumn {
+							t.Errorf("%s at %d:%d maps to %d:%d, want %s at %d:%d",
+								js, line+1, column+1, s.sourceLine+1, s.sourceColumn+1, tt.dart, sourceLine+1, sourceColumn+1)
+						}
+					}
+				}
+				if !found {
+					t.Errorf("%s at %d:%d is not mapped", js, line+1, column+1)
+				}
+			}
+		})
+	}
+}
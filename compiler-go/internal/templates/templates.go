@@ -0,0 +1,36 @@
+// Package templates holds the page templates copied to the output
+// directory of every build. The defaults are embedded in the binary; a
+// project can override any of them with a file of the same name in its
+// templates directory.
+package templates
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+//go:embed index.html styles.css
+var defaults embed.FS
+
+// Names lists the page templates, in the order they are written
+var Names = []string{"index.html", "styles.css"}
+
+// Read returns the template called name from dir, or the embedded default
+// if dir is empty or has no such file
+func Read(dir, name string) ([]byte, error) {
+	if dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return content, err
+		}
+	}
+	return defaults.ReadFile(name)
+}
+
+// Default returns the embedded default of the template called name
+func Default(name string) ([]byte, error) {
+	return defaults.ReadFile(name)
+}
@@ -1,47 +1,172 @@
 package ast
 
+import (
+	"fmt"
+	"sort"
+)
+
+// CompilationUnit is the result of parsing a single Dart file
+type CompilationUnit struct {
+	File    string
+	Imports []*Import      // import directives, in source order
+	Classes []*WidgetClass // widget classes, in source order
+	Entry   *WidgetNode    // widget passed to runApp in main, if any
+}
+
+// Import is an import directive. Prefixes and show and hide combinators
+// are not recorded.
+type Import struct {
+	URI  string // as written, such as "package:app/home.dart"
+	Span Span
+}
+
+// WidgetKind distinguishes stateless from stateful widget classes
+type WidgetKind int
+
+const (
+	Stateless WidgetKind = iota
+	Stateful
+)
+
+func (k WidgetKind) String() string {
+	if k == Stateful {
+		return "stateful"
+	}
+	return "stateless"
+}
+
+// WidgetClass is a StatelessWidget or StatefulWidget class declaration
+type WidgetClass struct {
+	Name       string
+	Kind       WidgetKind
+	Fields     []*Field
+	Parameters []*Parameter // parameters of the unnamed constructor
+	Methods    []*Method    // instance methods other than build
+	Build      *WidgetTree  // for stateful widgets, built by the State class
+	State      *StateClass  // for stateful widgets, the State class
+	Span       Span
+}
+
+// StateClass is the State<T> class of a stateful widget. Its instance
+// fields hold the widget's state.
+type StateClass struct {
+	Name    string
+	Fields  []*Field
+	Methods []*Method // instance methods other than build, in source order
+	Span    Span
+}
+
+// Method is an instance method declaration
+type Method struct {
+	Name string
+	Func *FuncLit
+	Span Span
+}
+
+// Field is an instance or static field of a class
+type Field struct {
+	Name        string
+	Type        string
+	Final       bool
+	Static      bool
+	Initializer Expr
+	Span        Span
+}
+
+// Parameter is a constructor, method or function literal parameter
+type Parameter struct {
+	Name     string
+	Type     string // empty for initializing formals such as this.title
+	Named    bool
+	Required bool
+	Field    bool // initializes the field of the same name (this.name)
+	Default  Expr
+	Span     Span
+}
+
 // WidgetTree represents the root of a Flutter widget tree
 type WidgetTree struct {
-	Root *WidgetNode
+	Stmts []Stmt // statements of a block body before the return of Root
+	Root  *WidgetNode
 }
 
-// WidgetNode represents a Flutter widget in the tree
+// WidgetNode represents a Flutter widget
 type WidgetNode struct {
 	Name       string
-	Properties map[string]PropertyValue
+	Arguments  []Value // positional arguments, in source order
+	Properties map[string]Value
+	Order      []string // names of Properties, in source order
 	Children   []*WidgetNode
+	Span       Span
 }
 
-// PropertyValue represents a value that can be assigned to a widget property
-type PropertyValue interface {
-	IsPropertyValue()
+// PropertyNames returns the names of the node's properties in source
+// order. Properties missing from Order, such as those added after
+// parsing, follow in sorted order.
+func (n *WidgetNode) PropertyNames() []string {
+	names := make([]string, 0, len(n.Properties))
+	seen := make(map[string]bool, len(n.Properties))
+	for _, name := range n.Order {
+		if _, ok := n.Properties[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	var rest []string
+	for name := range n.Properties {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
 }
 
-// StringValue represents a string property value
-type StringValue string
+// NodeSpan returns a span naming the unit's file
+func (u *CompilationUnit) NodeSpan() Span { return Span{File: u.File} }
 
-func (StringValue) IsPropertyValue() {}
+func (i *Import) NodeSpan() Span      { return i.Span }
+func (c *WidgetClass) NodeSpan() Span { return c.Span }
+func (c *StateClass) NodeSpan() Span  { return c.Span }
+func (m *Method) NodeSpan() Span      { return m.Span }
+func (f *Field) NodeSpan() Span       { return f.Span }
+func (p *Parameter) NodeSpan() Span   { return p.Span }
 
-// NumberValue represents a numeric property value
-type NumberValue float64
-
-func (NumberValue) IsPropertyValue() {}
-
-// BooleanValue represents a boolean property value
-type BooleanValue bool
-
-func (BooleanValue) IsPropertyValue() {}
+// NodeSpan returns the span of the tree's root widget
+func (t *WidgetTree) NodeSpan() Span {
+	if t.Root == nil {
+		return Span{}
+	}
+	return t.Root.Span
+}
 
-// WidgetValue represents a child widget property value
-type WidgetValue struct {
-	Widget *WidgetNode
+// Position is a location in a source file
+type Position struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // byte column, starting at 1
 }
 
-func (WidgetValue) IsPropertyValue() {}
+// Span is the range of source text a node was parsed from
+type Span struct {
+	File  string
+	Start Position
+	End   Position
+}
 
-// ListValue represents a list of property values
-type ListValue struct {
-	Values []PropertyValue
+// IsValid reports whether the span refers to a real source location
+func (s Span) IsValid() bool {
+	return s.Start.Line > 0
 }
 
-func (ListValue) IsPropertyValue() {}
+// String returns the span's start in file:line:col form
+func (s Span) String() string {
+	file := s.File
+	if file == "" {
+		file = "<input>"
+	}
+	if !s.IsValid() {
+		return file
+	}
+	return fmt.Sprintf("%s:%d:%d", file, s.Start.Line, s.Start.Column)
+}
@@ -0,0 +1,21 @@
+// Package ast declares the syntax tree the Vortex compiler builds from
+// Dart source: widget classes, the widget trees their build methods
+// return, and the Dart expressions and statements inside them. Tools such
+// as linters and code generators can import it to work with the output of
+// the parser.
+//
+// Widget arguments are Values, a sealed interface whose node kinds are
+// literals, enum references, widget constructors, lists, maps, closures,
+// identifiers and interpolated strings. Expressions and statements are
+// Exprs and Stmts. Every node records the Span of source it was parsed
+// from.
+//
+// # Stability
+//
+// Within a major version, exported names in this package are not removed
+// or renamed and the meaning of existing fields does not change. New node
+// kinds and new fields may be added in minor versions, so type switches
+// over Value, Expr or Stmt should have a default case. The interfaces are
+// sealed by unexported methods; types outside this package cannot
+// implement them.
+package ast
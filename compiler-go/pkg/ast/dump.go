@@ -0,0 +1,123 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Dump converts node and everything below it to maps, slices and scalars
+// that encode directly as JSON or YAML. Each node becomes a map with a
+// "kind" entry naming its type, such as "WidgetNode" or "Call", and a
+// "span" entry with the start and end of its source. Other entries are the
+// node's fields with the first letter lowercased; nil fields and empty
+// lists are left out. Widget properties are a list of name/value pairs in
+// source order, and the widget node of a Call, which repeats its
+// arguments, is omitted.
+//
+// Map keys are sorted when encoded, so the encoding of a tree is stable.
+// The set of entries follows the fields of this package and grows with
+// them.
+func Dump(node Node) map[string]any {
+	m, _ := dump(reflect.ValueOf(node)).(map[string]any)
+	return m
+}
+
+var (
+	nodeType       = reflect.TypeOf((*Node)(nil)).Elem()
+	spanType       = reflect.TypeOf(Span{})
+	widgetNodeType = reflect.TypeOf(WidgetNode{})
+	callType       = reflect.TypeOf(Call{})
+)
+
+func dump(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Pointer && v.Type().Implements(nodeType) {
+			m := dumpStruct(v.Elem())
+			m["kind"] = v.Elem().Type().Name()
+			return m
+		}
+		return dump(v.Elem())
+	case reflect.Struct:
+		if v.Type() == spanType {
+			return dumpSpan(v.Interface().(Span))
+		}
+		return dumpStruct(v)
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = dump(v.Index(i))
+		}
+		return list
+	}
+	if s, ok := v.Interface().(interface{ String() string }); ok {
+		return s.String()
+	}
+	return v.Interface()
+}
+
+func dumpStruct(v reflect.Value) map[string]any {
+	m := make(map[string]any)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		var value any
+		switch {
+		case !field.IsExported():
+			continue
+		case t == widgetNodeType && field.Name == "Order":
+			// Properties are listed in source order instead
+			continue
+		case t == widgetNodeType && field.Name == "Properties":
+			value = dumpProperties(v.Addr().Interface().(*WidgetNode))
+		case t == callType && field.Name == "Widget":
+			continue
+		default:
+			value = dump(v.Field(i))
+		}
+		if value != nil {
+			m[lowerCamel(field.Name)] = value
+		}
+	}
+	return m
+}
+
+// lowerCamel lowercases the leading word of a field name, treating an
+// initialism such as URI as one word
+func lowerCamel(name string) string {
+	n := 1
+	for n < len(name) && unicode.IsUpper(rune(name[n])) &&
+		(n+1 == len(name) || unicode.IsUpper(rune(name[n+1]))) {
+		n++
+	}
+	return strings.ToLower(name[:n]) + name[n:]
+}
+
+func dumpProperties(n *WidgetNode) any {
+	names := n.PropertyNames()
+	if len(names) == 0 {
+		return nil
+	}
+	properties := make([]any, len(names))
+	for i, name := range names {
+		properties[i] = map[string]any{"name": name, "value": dump(reflect.ValueOf(n.Properties[name]))}
+	}
+	return properties
+}
+
+func dumpSpan(s Span) any {
+	if !s.IsValid() {
+		return nil
+	}
+	position := func(p Position) map[string]any {
+		return map[string]any{"offset": p.Offset, "line": p.Line, "column": p.Column}
+	}
+	return map[string]any{"start": position(s.Start), "end": position(s.End)}
+}
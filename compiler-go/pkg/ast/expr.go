@@ -0,0 +1,300 @@
+package ast
+
+// Node is implemented by every declaration, value, expression and
+// statement node
+type Node interface {
+	NodeSpan() Span
+}
+
+// Expr is a Dart expression
+type Expr interface {
+	Node
+	isExpr()
+}
+
+// Stmt is a Dart statement
+type Stmt interface {
+	Node
+	isStmt()
+}
+
+// LiteralKind identifies the type of a literal
+type LiteralKind int
+
+const (
+	StringLiteral LiteralKind = iota
+	NumberLiteral
+	BoolLiteral
+	NullLiteral
+)
+
+func (k LiteralKind) String() string {
+	switch k {
+	case NumberLiteral:
+		return "number"
+	case BoolLiteral:
+		return "bool"
+	case NullLiteral:
+		return "null"
+	}
+	return "string"
+}
+
+// Literal is a string, number, boolean or null literal. Value holds the
+// string contents or the source text of other literals.
+type Literal struct {
+	Kind  LiteralKind
+	Value string
+	Span  Span
+}
+
+// StringInterpolation is a string literal containing $name or ${expr}.
+// Parts holds its string literals and interpolated expressions in source
+// order.
+type StringInterpolation struct {
+	Parts []Expr
+	Span  Span
+}
+
+// Ident is a reference to a variable, field, method, type or `this`
+type Ident struct {
+	Name string
+	Span Span
+}
+
+// Paren is a parenthesized expression
+type Paren struct {
+	X    Expr
+	Span Span
+}
+
+// Member is a member access such as a.b or a?.b
+type Member struct {
+	X         Expr
+	Name      string
+	NullAware bool
+	Span      Span
+}
+
+// Index is an index expression such as a[i]
+type Index struct {
+	X     Expr
+	Index Expr
+	Span  Span
+}
+
+// Argument is a positional or named argument of a call
+type Argument struct {
+	Name  string // empty for positional arguments
+	Value Expr
+}
+
+// Call is a function, method or constructor call. Widget is set when the
+// call has the shape of a constructor call such as Text('x') or
+// EdgeInsets.all(8).
+type Call struct {
+	Fun    Expr
+	Args   []Argument
+	Widget *WidgetNode
+	Span   Span
+}
+
+// Unary is a prefix or postfix operator expression. The null assertion
+// operator a! is a postfix "!".
+type Unary struct {
+	Op      string
+	X       Expr
+	Postfix bool
+	Span    Span
+}
+
+// Binary is a binary operator expression. For the type operators is, is!
+// and as, Y is an Ident naming the type.
+type Binary struct {
+	Op   string
+	X    Expr
+	Y    Expr
+	Span Span
+}
+
+// Conditional is a conditional expression c ? a : b
+type Conditional struct {
+	Cond Expr
+	Then Expr
+	Else Expr
+	Span Span
+}
+
+// Assign is an assignment, including compound assignments such as +=
+type Assign struct {
+	Op     string
+	Target Expr
+	Value  Expr
+	Span   Span
+}
+
+// FuncLit is a function literal or method body. Exactly one of Body and
+// Result is set: Body for block bodies, Result for arrow bodies.
+type FuncLit struct {
+	Params []*Parameter
+	Body   *Block
+	Result Expr
+	Async  bool
+	Span   Span
+}
+
+// ListLit is a list literal
+type ListLit struct {
+	Elements []Expr
+	Span     Span
+}
+
+// MapEntry is a key/value pair of a map literal
+type MapEntry struct {
+	Key   Expr
+	Value Expr
+}
+
+// MapLit is a map or set literal. Set literals have entries without keys.
+type MapLit struct {
+	Entries []MapEntry
+	Span    Span
+}
+
+// RawExpr is an expression the parser recognizes but does not model,
+// such as a cascade or a collection for element
+type RawExpr struct {
+	Text string
+	Span Span
+}
+
+// Block is a brace-delimited list of statements
+type Block struct {
+	Stmts []Stmt
+	Span  Span
+}
+
+// ExprStmt is an expression used as a statement
+type ExprStmt struct {
+	X    Expr
+	Span Span
+}
+
+// VarSpec is a single variable of a declaration
+type VarSpec struct {
+	Name  string
+	Value Expr // nil if the variable is not initialized
+}
+
+// VarDecl declares one or more local variables
+type VarDecl struct {
+	Type  string // empty for var, final and const without a type
+	Final bool
+	Vars  []VarSpec
+	Span  Span
+}
+
+// Return is a return statement
+type Return struct {
+	Value Expr // nil for a bare return
+	Span  Span
+}
+
+// If is an if statement. Else is nil when there is no else branch.
+type If struct {
+	Cond Expr
+	Then Stmt
+	Else Stmt
+	Span Span
+}
+
+// For is a C-style for loop
+type For struct {
+	Init   Stmt // nil, a VarDecl or an ExprStmt
+	Cond   Expr // nil for an infinite loop
+	Update []Expr
+	Body   Stmt
+	Span   Span
+}
+
+// ForIn is a for-in loop over an iterable
+type ForIn struct {
+	Name     string
+	Iterable Expr
+	Body     Stmt
+	Span     Span
+}
+
+// While is a while loop
+type While struct {
+	Cond Expr
+	Body Stmt
+	Span Span
+}
+
+// Branch is a break or continue statement
+type Branch struct {
+	Keyword string
+	Span    Span
+}
+
+// RawStmt is a statement the parser recognizes but does not model, such
+// as switch or try
+type RawStmt struct {
+	Text string
+	Span Span
+}
+
+func (e *Literal) NodeSpan() Span             { return e.Span }
+func (e *StringInterpolation) NodeSpan() Span { return e.Span }
+func (e *Ident) NodeSpan() Span               { return e.Span }
+func (e *Paren) NodeSpan() Span               { return e.Span }
+func (e *Member) NodeSpan() Span              { return e.Span }
+func (e *Index) NodeSpan() Span               { return e.Span }
+func (e *Call) NodeSpan() Span                { return e.Span }
+func (e *Unary) NodeSpan() Span               { return e.Span }
+func (e *Binary) NodeSpan() Span              { return e.Span }
+func (e *Conditional) NodeSpan() Span         { return e.Span }
+func (e *Assign) NodeSpan() Span              { return e.Span }
+func (e *FuncLit) NodeSpan() Span             { return e.Span }
+func (e *ListLit) NodeSpan() Span             { return e.Span }
+func (e *MapLit) NodeSpan() Span              { return e.Span }
+func (e *RawExpr) NodeSpan() Span             { return e.Span }
+
+func (s *Block) NodeSpan() Span    { return s.Span }
+func (s *ExprStmt) NodeSpan() Span { return s.Span }
+func (s *VarDecl) NodeSpan() Span  { return s.Span }
+func (s *Return) NodeSpan() Span   { return s.Span }
+func (s *If) NodeSpan() Span       { return s.Span }
+func (s *For) NodeSpan() Span      { return s.Span }
+func (s *ForIn) NodeSpan() Span    { return s.Span }
+func (s *While) NodeSpan() Span    { return s.Span }
+func (s *Branch) NodeSpan() Span   { return s.Span }
+func (s *RawStmt) NodeSpan() Span  { return s.Span }
+
+func (*Literal) isExpr()             {}
+func (*StringInterpolation) isExpr() {}
+func (*Ident) isExpr()               {}
+func (*Paren) isExpr()               {}
+func (*Member) isExpr()              {}
+func (*Index) isExpr()               {}
+func (*Call) isExpr()                {}
+func (*Unary) isExpr()               {}
+func (*Binary) isExpr()              {}
+func (*Conditional) isExpr()         {}
+func (*Assign) isExpr()              {}
+func (*FuncLit) isExpr()             {}
+func (*ListLit) isExpr()             {}
+func (*MapLit) isExpr()              {}
+func (*RawExpr) isExpr()             {}
+
+func (*Block) isStmt()    {}
+func (*ExprStmt) isStmt() {}
+func (*VarDecl) isStmt()  {}
+func (*Return) isStmt()   {}
+func (*If) isStmt()       {}
+func (*For) isStmt()      {}
+func (*ForIn) isStmt()    {}
+func (*While) isStmt()    {}
+func (*Branch) isStmt()   {}
+func (*RawStmt) isStmt()  {}
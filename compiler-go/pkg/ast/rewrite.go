@@ -0,0 +1,118 @@
+package ast
+
+import "fmt"
+
+// Rewrite replaces the values below node, which is typically a
+// *CompilationUnit. Values are visited bottom-up: f is called for each
+// value after the values inside it have been rewritten, and its result
+// takes the value's place. Returning the value unchanged keeps it and
+// returning nil removes it from its widget, list or map.
+//
+// Widgets built inside expressions, such as a closure that returns a
+// widget, are rewritten as well. Where the tree requires a widget, as for
+// a widget's children or a widget built in an expression, f must return a
+// *WidgetNode; children may also be removed. A removed build root leaves
+// the build method returning null. Rewrite panics if f returns a value
+// that cannot take the place of the one it replaces.
+func Rewrite(node Node, f func(Value) Value) {
+	r := &rewriter{f: f}
+	Inspect(node, func(n Node) bool {
+		switch n := n.(type) {
+		case *CompilationUnit:
+			if n.Entry != nil {
+				n.Entry = r.widget(n.Entry, false)
+			}
+		case *WidgetTree:
+			// The statements before the root are inspected next
+			if n.Root != nil {
+				n.Root = r.widget(n.Root, true)
+			}
+		case *WidgetNode:
+			// Reached through the tree, call or unit that holds it
+			return false
+		case *Call:
+			if n.Widget != nil {
+				n.Widget = r.widget(n.Widget, false)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+type rewriter struct {
+	f func(Value) Value
+}
+
+// value rewrites v and the values inside it, returning nil if it is
+// removed
+func (r *rewriter) value(v Value) Value {
+	switch v := v.(type) {
+	case *WidgetNode:
+		r.children(v)
+	case *ListValue:
+		v.Elements = r.values(v.Elements)
+	case *MapValue:
+		entries := v.Entries[:0]
+		for _, entry := range v.Entries {
+			key, value := r.value(entry.Key), r.value(entry.Value)
+			if key != nil && value != nil {
+				entries = append(entries, MapValueEntry{Key: key, Value: value})
+			}
+		}
+		v.Entries = entries
+	case *FuncLit, *StringInterpolation, *ExprValue:
+		// Only widgets built by the expression can be rewritten
+		Inspect(v, func(n Node) bool {
+			if call, ok := n.(*Call); ok && call.Widget != nil {
+				call.Widget = r.widget(call.Widget, false)
+				return false
+			}
+			return true
+		})
+	}
+	return r.f(v)
+}
+
+// widget rewrites a value that must remain a widget. A nil result is
+// allowed if removable is set.
+func (r *rewriter) widget(w *WidgetNode, removable bool) *WidgetNode {
+	switch v := r.value(w).(type) {
+	case *WidgetNode:
+		return v
+	case nil:
+		if removable {
+			return nil
+		}
+	}
+	panic(fmt.Sprintf("ast.Rewrite: widget %s at %s must be replaced by a widget", w.Name, w.Span))
+}
+
+func (r *rewriter) values(values []Value) []Value {
+	kept := values[:0]
+	for _, v := range values {
+		if v = r.value(v); v != nil {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// children rewrites the arguments, properties and children of a widget
+func (r *rewriter) children(w *WidgetNode) {
+	w.Arguments = r.values(w.Arguments)
+	for _, name := range w.PropertyNames() {
+		if v := r.value(w.Properties[name]); v != nil {
+			w.Properties[name] = v
+		} else {
+			delete(w.Properties, name)
+		}
+	}
+	children := w.Children[:0]
+	for _, child := range w.Children {
+		if child = r.widget(child, true); child != nil {
+			children = append(children, child)
+		}
+	}
+	w.Children = children
+}
@@ -0,0 +1,174 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"compiler-go/pkg/ast"
+)
+
+func TestRewriteOrder(t *testing.T) {
+	unit := parse(t, homeSource)
+	var got []string
+	ast.Rewrite(unit, func(v ast.Value) ast.Value {
+		got = append(got, describe(v))
+		return v
+	})
+	// The unit's entry comes first, then each build method's tree bottom-up,
+	// with the properties of a widget in source order before its children
+	want := []string{
+		`StringValue "Hi"`, `WidgetNode Home`,
+		`EnumRef MainAxisAlignment.center`,
+		`IdentRef title`, `WidgetNode Text`,
+		`IdentRef gap`, `WidgetNode EdgeInsets_all`, `StringValue "a"`, `WidgetNode Text`, `WidgetNode Padding`,
+		`FuncLit`, `StringValue "b"`, `WidgetNode Text`, `WidgetNode ElevatedButton`,
+		`WidgetNode Column`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Rewrite called f with\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRewriteReplace(t *testing.T) {
+	unit := parse(t, homeSource)
+	ast.Rewrite(unit, func(v ast.Value) ast.Value {
+		switch v := v.(type) {
+		case *ast.StringValue:
+			return &ast.StringValue{Value: strings.ToUpper(v.Value), Span: v.Span}
+		case *ast.WidgetNode:
+			if v.Name == "Padding" {
+				// Replaced by its child, whose string is already rewritten
+				return v.Properties["child"]
+			}
+		}
+		return v
+	})
+	want := `CompilationUnit
+  Import package:flutter/material.dart
+  WidgetClass Home
+    Field title
+    Parameter title
+    Method greet
+    WidgetTree
+      VarDecl
+        Literal "8"
+      WidgetNode Column
+        EnumRef MainAxisAlignment.center
+        WidgetNode Text
+          IdentRef title
+        WidgetNode Text
+          StringValue "A"
+        WidgetNode ElevatedButton
+          FuncLit
+          WidgetNode Text
+            StringValue "B"
+  WidgetNode Home
+    StringValue "HI"
+`
+	got := outline(unit, func(n ast.Node) bool {
+		_, ok := n.(*ast.Method)
+		_, fn := n.(*ast.FuncLit)
+		return ok || fn
+	})
+	if got != want {
+		t.Errorf("rewritten unit\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRewriteRemove(t *testing.T) {
+	unit := parse(t, homeSource)
+	ast.Rewrite(unit, func(v ast.Value) ast.Value {
+		switch v := v.(type) {
+		case *ast.EnumRef, *ast.FuncLit:
+			return nil
+		case *ast.WidgetNode:
+			if v.Name == "Text" {
+				return nil
+			}
+		}
+		return v
+	})
+	want := `WidgetTree
+  VarDecl
+    Literal "8"
+  WidgetNode Column
+    WidgetNode Padding
+      WidgetNode EdgeInsets_all
+        IdentRef gap
+    WidgetNode ElevatedButton
+`
+	build := unit.Classes[0].Build
+	if got := outline(build, nil); got != want {
+		t.Errorf("rewritten build\n%s\nwant\n%s", got, want)
+	}
+	column := build.Root
+	if len(column.Properties) != 0 || len(column.Children) != 2 {
+		t.Errorf("Column has properties %v and %d children, want none and 2", column.PropertyNames(), len(column.Children))
+	}
+	if names := column.Children[1].PropertyNames(); len(names) != 0 {
+		t.Errorf("ElevatedButton has properties %v, want none", names)
+	}
+}
+
+func TestRewriteRoot(t *testing.T) {
+	unit := parse(t, homeSource)
+	ast.Rewrite(unit, func(v ast.Value) ast.Value {
+		if w, ok := v.(*ast.WidgetNode); ok && w.Name == "Column" {
+			return nil
+		}
+		return v
+	})
+	if root := unit.Classes[0].Build.Root; root != nil {
+		t.Errorf("build root is %s, want nil", root.Name)
+	}
+}
+
+func TestRewriteExpressionWidgets(t *testing.T) {
+	unit := parse(t, `import 'package:flutter/material.dart';
+
+class Home extends StatelessWidget {
+  @override
+  Widget build(BuildContext context) {
+    return Builder(builder: (context) => Text('$context'), child: Text('x'));
+  }
+}
+`)
+	ast.Rewrite(unit, func(v ast.Value) ast.Value {
+		if w, ok := v.(*ast.WidgetNode); ok && w.Name == "Text" {
+			return &ast.WidgetNode{Name: "Label", Properties: map[string]ast.Value{}, Span: w.Span}
+		}
+		return v
+	})
+	var names []string
+	ast.Inspect(unit, func(n ast.Node) bool {
+		if call, ok := n.(*ast.Call); ok && call.Widget != nil {
+			names = append(names, "call "+call.Widget.Name)
+		}
+		if w, ok := n.(*ast.WidgetNode); ok {
+			names = append(names, w.Name)
+		}
+		return true
+	})
+	if got, want := strings.Join(names, ", "), "Builder, call Label, Label"; got != want {
+		t.Errorf("widgets after Rewrite: %s, want %s", got, want)
+	}
+}
+
+func TestRewritePanics(t *testing.T) {
+	unit := parse(t, homeSource)
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Rewrite did not panic when the entry widget was replaced by a string")
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, "widget Home") {
+			t.Errorf("panic %q does not name the widget", r)
+		}
+	}()
+	ast.Rewrite(unit, func(v ast.Value) ast.Value {
+		if w, ok := v.(*ast.WidgetNode); ok && w.Name == "Home" {
+			return &ast.StringValue{Value: "Home"}
+		}
+		return v
+	})
+}
@@ -0,0 +1,114 @@
+package ast
+
+// Value is the value of a widget argument. It is a sealed interface: the
+// only implementations are the node kinds declared in this package.
+//
+//   - literals: *StringValue, *NumberValue, *BoolValue and *NullValue
+//   - enum and static member references: *EnumRef
+//   - widget constructors: *WidgetNode
+//   - collections: *ListValue and *MapValue
+//   - closures: *FuncLit
+//   - identifiers: *IdentRef
+//   - interpolated strings: *StringInterpolation
+//   - other expressions: *ExprValue, or *RawValue when the expression
+//     calls a static method that has no runtime equivalent
+type Value interface {
+	Node
+	isValue()
+}
+
+// StringValue is a string literal without interpolation. Value holds the
+// decoded contents.
+type StringValue struct {
+	Value string
+	Span  Span
+}
+
+// NumberValue is a number literal, including a negated one
+type NumberValue struct {
+	Value float64
+	Span  Span
+}
+
+// BoolValue is true or false
+type BoolValue struct {
+	Value bool
+	Span  Span
+}
+
+// NullValue is null
+type NullValue struct {
+	Span Span
+}
+
+// EnumRef is a reference to an enum value or static member, such as
+// MainAxisAlignment.center or Colors.grey[300]
+type EnumRef struct {
+	Name string // source form of the reference
+	Span Span
+}
+
+// IdentRef is a reference to a variable or field, such as title,
+// this.title or widget.title
+type IdentRef struct {
+	Name string // source form of the reference
+	Span Span
+}
+
+// ListValue is a list literal
+type ListValue struct {
+	Elements []Value
+	Span     Span
+}
+
+// MapValue is a map literal
+type MapValue struct {
+	Entries []MapValueEntry
+	Span    Span
+}
+
+// MapValueEntry is a key: value entry of a map literal
+type MapValueEntry struct {
+	Key   Value
+	Value Value
+}
+
+// ExprValue is any other expression, such as a conditional, arithmetic or
+// a method call, and is compiled as is
+type ExprValue struct {
+	X    Expr
+	Span Span
+}
+
+// RawValue is an expression that has no structured form, such as a call
+// to Theme.of(context), kept as its source text. Generators leave it out.
+type RawValue struct {
+	Text string
+	Span Span
+}
+
+func (v *StringValue) NodeSpan() Span { return v.Span }
+func (v *NumberValue) NodeSpan() Span { return v.Span }
+func (v *BoolValue) NodeSpan() Span   { return v.Span }
+func (v *NullValue) NodeSpan() Span   { return v.Span }
+func (v *EnumRef) NodeSpan() Span     { return v.Span }
+func (v *IdentRef) NodeSpan() Span    { return v.Span }
+func (v *WidgetNode) NodeSpan() Span  { return v.Span }
+func (v *ListValue) NodeSpan() Span   { return v.Span }
+func (v *MapValue) NodeSpan() Span    { return v.Span }
+func (v *ExprValue) NodeSpan() Span   { return v.Span }
+func (v *RawValue) NodeSpan() Span    { return v.Span }
+
+func (*StringValue) isValue()         {}
+func (*NumberValue) isValue()         {}
+func (*BoolValue) isValue()           {}
+func (*NullValue) isValue()           {}
+func (*EnumRef) isValue()             {}
+func (*IdentRef) isValue()            {}
+func (*WidgetNode) isValue()          {}
+func (*ListValue) isValue()           {}
+func (*MapValue) isValue()            {}
+func (*FuncLit) isValue()             {}
+func (*StringInterpolation) isValue() {}
+func (*ExprValue) isValue()           {}
+func (*RawValue) isValue()            {}
@@ -0,0 +1,220 @@
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children of
+// node with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order. It starts by calling
+// v.Visit(node); if the visitor returned is not nil, Walk is invoked
+// recursively with it for each non-nil child of node, followed by a call
+// of Visit(nil).
+//
+// Properties are visited in source order. The widget node of a Call is
+// derived from the call's arguments and is not visited separately.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	// Declarations
+	case *CompilationUnit:
+		for _, imp := range n.Imports {
+			Walk(v, imp)
+		}
+		for _, class := range n.Classes {
+			Walk(v, class)
+		}
+		if n.Entry != nil {
+			Walk(v, n.Entry)
+		}
+	case *Import:
+		// leaf
+	case *WidgetClass:
+		walkFields(v, n.Fields)
+		for _, param := range n.Parameters {
+			Walk(v, param)
+		}
+		walkMethods(v, n.Methods)
+		if n.State != nil {
+			Walk(v, n.State)
+		}
+		if n.Build != nil {
+			Walk(v, n.Build)
+		}
+	case *StateClass:
+		walkFields(v, n.Fields)
+		walkMethods(v, n.Methods)
+	case *Method:
+		Walk(v, n.Func)
+	case *Field:
+		walkExpr(v, n.Initializer)
+	case *Parameter:
+		walkExpr(v, n.Default)
+	case *WidgetTree:
+		for _, stmt := range n.Stmts {
+			Walk(v, stmt)
+		}
+		if n.Root != nil {
+			Walk(v, n.Root)
+		}
+
+	// Values
+	case *WidgetNode:
+		walkValues(v, n.Arguments)
+		for _, name := range n.PropertyNames() {
+			Walk(v, n.Properties[name])
+		}
+		for _, child := range n.Children {
+			Walk(v, child)
+		}
+	case *ListValue:
+		walkValues(v, n.Elements)
+	case *MapValue:
+		for _, entry := range n.Entries {
+			Walk(v, entry.Key)
+			Walk(v, entry.Value)
+		}
+	case *ExprValue:
+		Walk(v, n.X)
+	case *StringValue, *NumberValue, *BoolValue, *NullValue, *EnumRef, *IdentRef, *RawValue:
+		// leaves
+
+	// Expressions
+	case *StringInterpolation:
+		for _, part := range n.Parts {
+			Walk(v, part)
+		}
+	case *Paren:
+		Walk(v, n.X)
+	case *Member:
+		Walk(v, n.X)
+	case *Index:
+		Walk(v, n.X)
+		Walk(v, n.Index)
+	case *Call:
+		Walk(v, n.Fun)
+		for _, arg := range n.Args {
+			Walk(v, arg.Value)
+		}
+	case *Unary:
+		Walk(v, n.X)
+	case *Binary:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *Conditional:
+		Walk(v, n.Cond)
+		Walk(v, n.Then)
+		Walk(v, n.Else)
+	case *Assign:
+		Walk(v, n.Target)
+		Walk(v, n.Value)
+	case *FuncLit:
+		for _, param := range n.Params {
+			Walk(v, param)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+		walkExpr(v, n.Result)
+	case *ListLit:
+		for _, element := range n.Elements {
+			Walk(v, element)
+		}
+	case *MapLit:
+		for _, entry := range n.Entries {
+			walkExpr(v, entry.Key)
+			Walk(v, entry.Value)
+		}
+	case *Literal, *Ident, *RawExpr:
+		// leaves
+
+	// Statements
+	case *Block:
+		for _, stmt := range n.Stmts {
+			Walk(v, stmt)
+		}
+	case *ExprStmt:
+		Walk(v, n.X)
+	case *VarDecl:
+		for _, spec := range n.Vars {
+			walkExpr(v, spec.Value)
+		}
+	case *Return:
+		walkExpr(v, n.Value)
+	case *If:
+		Walk(v, n.Cond)
+		Walk(v, n.Then)
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
+	case *For:
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+		walkExpr(v, n.Cond)
+		for _, update := range n.Update {
+			Walk(v, update)
+		}
+		Walk(v, n.Body)
+	case *ForIn:
+		Walk(v, n.Iterable)
+		Walk(v, n.Body)
+	case *While:
+		Walk(v, n.Cond)
+		Walk(v, n.Body)
+	case *Branch, *RawStmt:
+		// leaves
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkExpr(v Visitor, x Expr) {
+	if x != nil {
+		Walk(v, x)
+	}
+}
+
+func walkValues(v Visitor, values []Value) {
+	for _, value := range values {
+		Walk(v, value)
+	}
+}
+
+func walkFields(v Visitor, fields []*Field) {
+	for _, field := range fields {
+		Walk(v, field)
+	}
+}
+
+func walkMethods(v Visitor, methods []*Method) {
+	for _, method := range methods {
+		Walk(v, method)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,220 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"compiler-go/internal/parser"
+	"compiler-go/pkg/ast"
+)
+
+const homeSource = `import 'package:flutter/material.dart';
+
+void main() {
+  runApp(const Home(title: 'Hi'));
+}
+
+class Home extends StatelessWidget {
+  final String title;
+
+  const Home({super.key, required this.title});
+
+  void greet(String name) {
+    print('Hello $name');
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    final gap = 8;
+    return Column(
+      mainAxisAlignment: MainAxisAlignment.center,
+      children: [
+        Text(title),
+        Padding(padding: EdgeInsets.all(gap), child: Text('a')),
+        ElevatedButton(onPressed: () => greet(title), child: Text('b')),
+      ],
+    );
+  }
+}
+`
+
+// parse parses a Dart file, failing on any error
+func parse(t *testing.T, src string) *ast.CompilationUnit {
+	t.Helper()
+	unit, diags := parser.NewParser().ParseUnit("home.dart", src)
+	if err := diags.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return unit
+}
+
+// describe returns the type of a node and its name or value, if any
+func describe(n ast.Node) string {
+	kind := strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+	switch n := n.(type) {
+	case *ast.Import:
+		return kind + " " + n.URI
+	case *ast.WidgetClass:
+		return kind + " " + n.Name
+	case *ast.Method:
+		return kind + " " + n.Name
+	case *ast.Field:
+		return kind + " " + n.Name
+	case *ast.Parameter:
+		return kind + " " + n.Name
+	case *ast.WidgetNode:
+		return kind + " " + n.Name
+	case *ast.Ident:
+		return kind + " " + n.Name
+	case *ast.Literal:
+		return fmt.Sprintf("%s %q", kind, n.Value)
+	case *ast.StringValue:
+		return fmt.Sprintf("%s %q", kind, n.Value)
+	case *ast.StringInterpolation:
+		return fmt.Sprintf("%s (%d parts)", kind, len(n.Parts))
+	case *ast.EnumRef:
+		return kind + " " + n.Name
+	case *ast.IdentRef:
+		return kind + " " + n.Name
+	}
+	return kind
+}
+
+// outline returns the nodes Inspect visits below node, indented by depth,
+// skipping the children of nodes for which skip reports true
+func outline(node ast.Node, skip func(ast.Node) bool) string {
+	var b strings.Builder
+	depth := 0
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			depth--
+			return false
+		}
+		fmt.Fprintf(&b, "%*s%s\n", depth*2, "", describe(n))
+		if skip != nil && skip(n) {
+			return false
+		}
+		depth++
+		return true
+	})
+	return b.String()
+}
+
+func TestInspectOrder(t *testing.T) {
+	unit := parse(t, homeSource)
+	want := `CompilationUnit
+  Import package:flutter/material.dart
+  WidgetClass Home
+    Field title
+    Parameter title
+    Method greet
+      FuncLit
+        Parameter name
+        Block
+          ExprStmt
+            Call
+              Ident print
+              StringInterpolation (2 parts)
+                Literal "Hello "
+                Ident name
+    WidgetTree
+      VarDecl
+        Literal "8"
+      WidgetNode Column
+        EnumRef MainAxisAlignment.center
+        WidgetNode Text
+          IdentRef title
+        WidgetNode Padding
+          WidgetNode EdgeInsets_all
+            IdentRef gap
+          WidgetNode Text
+            StringValue "a"
+        WidgetNode ElevatedButton
+          FuncLit
+            Call
+              Ident greet
+              Ident title
+          WidgetNode Text
+            StringValue "b"
+  WidgetNode Home
+    StringValue "Hi"
+`
+	if got := outline(unit, nil); got != want {
+		t.Errorf("Inspect visited\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInspectPrune(t *testing.T) {
+	unit := parse(t, homeSource)
+	got := outline(unit, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Method, *ast.FuncLit:
+			return true
+		case *ast.WidgetNode:
+			return n.Name == "Padding"
+		}
+		return false
+	})
+	want := `CompilationUnit
+  Import package:flutter/material.dart
+  WidgetClass Home
+    Field title
+    Parameter title
+    Method greet
+    WidgetTree
+      VarDecl
+        Literal "8"
+      WidgetNode Column
+        EnumRef MainAxisAlignment.center
+        WidgetNode Text
+          IdentRef title
+        WidgetNode Padding
+        WidgetNode ElevatedButton
+          FuncLit
+          WidgetNode Text
+            StringValue "b"
+  WidgetNode Home
+    StringValue "Hi"
+`
+	if got != want {
+		t.Errorf("Inspect visited\n%s\nwant\n%s", got, want)
+	}
+}
+
+// depthVisitor records the depth of each node it visits and counts the
+// calls of Visit(nil) that end the children of a node. Each visitor visits
+// the children of one node.
+type depthVisitor struct {
+	depth  int
+	nodes  *[]string
+	closed *int
+}
+
+func (v depthVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		*v.closed++
+		return nil
+	}
+	*v.nodes = append(*v.nodes, fmt.Sprintf("%d %s", v.depth, describe(node)))
+	return depthVisitor{v.depth + 1, v.nodes, v.closed}
+}
+
+func TestWalk(t *testing.T) {
+	unit := parse(t, homeSource)
+	var nodes []string
+	closed := 0
+	ast.Walk(depthVisitor{nodes: &nodes, closed: &closed}, unit)
+	if closed != len(nodes) {
+		t.Errorf("Walk visited %d nodes but called Visit(nil) %d times", len(nodes), closed)
+	}
+	for _, want := range []string{"0 CompilationUnit", "2 Method greet", "3 WidgetNode Column", "5 WidgetNode EdgeInsets_all", "1 WidgetNode Home"} {
+		found := false
+		for _, node := range nodes {
+			found = found || node == want
+		}
+		if !found {
+			t.Errorf("Walk did not visit %q; visited %q", want, nodes)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package pass runs compiler passes over parsed compilation units.
+//
+// A pass transforms or checks the syntax tree of a unit after parsing and
+// before code generation, typically with ast.Inspect or ast.Rewrite:
+//
+//	p := pass.NewPipeline(pass.StripWidgets("DebugBanner"))
+//	p.Add(pass.Pass{Name: "inline-consts", Run: inlineConsts})
+//	if err := p.Run(unit); err != nil {
+//		// ...
+//	}
+package pass
+
+import (
+	"fmt"
+
+	"compiler-go/pkg/ast"
+)
+
+// Pass is a named transformation of a compilation unit. Run may modify
+// the unit in place and returns an error to stop the pipeline.
+type Pass struct {
+	Name string
+	Run  func(unit *ast.CompilationUnit) error
+}
+
+// Pipeline is an ordered list of passes
+type Pipeline struct {
+	passes []Pass
+}
+
+// NewPipeline creates a pipeline running the given passes in order
+func NewPipeline(passes ...Pass) *Pipeline {
+	return &Pipeline{passes: passes}
+}
+
+// Add appends a pass to the end of the pipeline
+func (p *Pipeline) Add(pass Pass) {
+	p.passes = append(p.passes, pass)
+}
+
+// Passes returns the names of the pipeline's passes, in order
+func (p *Pipeline) Passes() []string {
+	names := make([]string, len(p.passes))
+	for i, pass := range p.passes {
+		names[i] = pass.Name
+	}
+	return names
+}
+
+// Run runs each pass on unit in order, stopping at the first error
+func (p *Pipeline) Run(unit *ast.CompilationUnit) error {
+	for _, pass := range p.passes {
+		if err := pass.Run(unit); err != nil {
+			return fmt.Errorf("pass %s: %w", pass.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package pass_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"compiler-go/internal/parser"
+	"compiler-go/pkg/ast"
+	"compiler-go/pkg/pass"
+)
+
+const source = `import 'package:flutter/material.dart';
+
+void main() {
+  runApp(const DebugBanner(child: App()));
+}
+
+class App extends StatelessWidget {
+  const App({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Column(children: [
+      DebugBanner(child: Padding(padding: EdgeInsets.all(8), child: Text('a'))),
+      PerformanceOverlay(),
+      ElevatedButton(onPressed: () => print('b'), child: DebugBanner(child: DebugBanner(child: Text('b')))),
+    ]);
+  }
+}
+`
+
+func parse(t *testing.T) *ast.CompilationUnit {
+	t.Helper()
+	unit, diags := parser.NewParser().ParseUnit("app.dart", source)
+	if err := diags.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return unit
+}
+
+// widgets returns the names of the widgets of the unit in the order
+// ast.Inspect visits them
+func widgets(unit *ast.CompilationUnit) string {
+	var names []string
+	ast.Inspect(unit, func(n ast.Node) bool {
+		if w, ok := n.(*ast.WidgetNode); ok {
+			names = append(names, w.Name)
+		}
+		return true
+	})
+	return strings.Join(names, " ")
+}
+
+func TestPipeline(t *testing.T) {
+	var ran []string
+	record := func(name string, err error) pass.Pass {
+		return pass.Pass{Name: name, Run: func(*ast.CompilationUnit) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+	failure := errors.New("failed")
+	p := pass.NewPipeline(record("a", nil), record("b", nil))
+	p.Add(record("c", failure))
+	p.Add(record("d", nil))
+
+	if got := strings.Join(p.Passes(), " "); got != "a b c d" {
+		t.Errorf("Passes() = %s, want a b c d", got)
+	}
+	err := p.Run(parse(t))
+	if !errors.Is(err, failure) || err.Error() != "pass c: failed" {
+		t.Errorf("Run() = %v, want pass c: failed", err)
+	}
+	if got := strings.Join(ran, " "); got != "a b c" {
+		t.Errorf("ran %s, want a b c", got)
+	}
+}
+
+func TestStripWidgets(t *testing.T) {
+	unit := parse(t)
+	if err := pass.NewPipeline(pass.StripWidgets("DebugBanner", "PerformanceOverlay")).Run(unit); err != nil {
+		t.Fatal(err)
+	}
+	// A stripped widget with a child is replaced by it, any other by an
+	// empty SizedBox
+	want := "Column Padding EdgeInsets_all Text SizedBox ElevatedButton Text App"
+	if got := widgets(unit); got != want {
+		t.Errorf("widgets after stripping: %s, want %s", got, want)
+	}
+	if box := unit.Classes[0].Build.Root.Children[1]; box.Properties == nil || box.Children == nil {
+		t.Errorf("the SizedBox replacing a widget has nil properties or children")
+	}
+}
+
+func TestStripWidgetsChain(t *testing.T) {
+	unit := parse(t)
+	var seen string
+	p := pass.NewPipeline(
+		pass.StripWidgets("DebugBanner"),
+		pass.Pass{Name: "rename", Run: func(unit *ast.CompilationUnit) error {
+			seen = widgets(unit)
+			ast.Rewrite(unit, func(v ast.Value) ast.Value {
+				if w, ok := v.(*ast.WidgetNode); ok && w.Name == "PerformanceOverlay" {
+					w.Name = "DebugBanner"
+				}
+				return v
+			})
+			return nil
+		}},
+		pass.StripWidgets("DebugBanner"),
+	)
+	if err := p.Run(unit); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Column Padding EdgeInsets_all Text PerformanceOverlay ElevatedButton Text App"; seen != want {
+		t.Errorf("second pass saw %s, want %s", seen, want)
+	}
+	if got, want := widgets(unit), "Column Padding EdgeInsets_all Text SizedBox ElevatedButton Text App"; got != want {
+		t.Errorf("widgets after the pipeline: %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+package pass
+
+import "compiler-go/pkg/ast"
+
+// StripWidgets returns a pass that removes the named widgets, such as
+// debug-only wrappers, from every widget tree. A stripped widget with a
+// child widget is replaced by its child; any other is replaced by an
+// empty SizedBox so that it still occupies a widget position.
+func StripWidgets(names ...string) Pass {
+	strip := make(map[string]bool, len(names))
+	for _, name := range names {
+		strip[name] = true
+	}
+	return Pass{
+		Name: "strip-widgets",
+		Run: func(unit *ast.CompilationUnit) error {
+			ast.Rewrite(unit, func(v ast.Value) ast.Value {
+				w, ok := v.(*ast.WidgetNode)
+				if !ok || !strip[w.Name] {
+					return v
+				}
+				if child, ok := w.Properties["child"].(*ast.WidgetNode); ok {
+					return child
+				}
+				return &ast.WidgetNode{
+					Name:       "SizedBox",
+					Properties: make(map[string]ast.Value),
+					Children:   make([]*ast.WidgetNode, 0),
+					Span:       w.Span,
+				}
+			})
+			return nil
+		},
+	}
+}